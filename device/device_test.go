@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+func TestDeviceLs(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		tests := []struct {
+			args   []string
+			expect []string
+		}{
+			{nil, []string{"pci-100", "sio-400", "ide-200", "ide-201", "lsilogic-1000", "disk-1000-0", "ethernet-0"}},
+			{[]string{"disk-*"}, []string{"disk-1000-0"}},
+			{[]string{"ethernet-0", "lsilogic-1000"}, []string{"ethernet-0", "lsilogic-1000"}},
+		}
+
+		for _, test := range tests {
+			out, err := govc(t, c, append([]string{"device.ls", "-vm", "VM0", "-json"}, test.args...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var res struct {
+				Devices []struct {
+					Name    string
+					Type    string
+					Summary string
+				}
+			}
+
+			if err = json.Unmarshal([]byte(out), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, device := range res.Devices {
+				names = append(names, device.Name)
+			}
+
+			if fmt.Sprint(names) != fmt.Sprint(test.expect) {
+				t.Errorf("%v: expected %v, got %v", test.args, test.expect, names)
+			}
+		}
+
+		out, err := govc(t, c, "device.ls", "-vm", "VM0", "ethernet-0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out, "ethernet-0") || !strings.Contains(out, "VirtualE1000") || !strings.Contains(out, "vmnet8") {
+			t.Errorf("unexpected output: %s", out)
+		}
+
+		if _, err = govc(t, c, "device.ls", "-vm", "VM0", "enoent"); err == nil {
+			t.Error("expected an error listing a missing device")
+		}
+
+		if _, err = govc(t, c, "device.ls", "-vm", "enoent"); err == nil {
+			t.Error("expected an error listing the devices of a missing VM")
+		}
+	})
+}
+
+func TestDeviceInfo(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "VM0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := govc(t, c, "device.info", "-vm", "VM0", "-json", "disk-*", "ethernet-0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res struct {
+			Devices []struct {
+				Name            string
+				Type            string
+				ControllerKey   int32
+				CapacityInBytes int64
+				MacAddress      string
+				Backing         struct {
+					FileName string
+				}
+			}
+		}
+
+		if err = json.Unmarshal([]byte(out), &res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res.Devices) != 2 {
+			t.Fatalf("expected 2 devices, got %d", len(res.Devices))
+		}
+
+		disk, nic := res.Devices[0], res.Devices[1]
+
+		if disk.Name != "disk-1000-0" || disk.Type != "VirtualDisk" || disk.ControllerKey != 1000 || disk.CapacityInBytes != 16<<30 ||
+			disk.Backing.FileName != filepath.Join(filepath.Dir(vmxPath), "VM0.vmdk") {
+			t.Errorf("unexpected disk: %+v", disk)
+		}
+
+		if nic.Name != "ethernet-0" || nic.Type != "VirtualE1000" || nic.MacAddress == "" {
+			t.Errorf("unexpected nic: %+v", nic)
+		}
+
+		out, err = govc(t, c, "device.info", "-vm", "VM0", "lsilogic-1000")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, line := range []string{"Name:", "lsilogic-1000", "SCSI controller 0", "disk-1000-0"} {
+			if !strings.Contains(out, line) {
+				t.Errorf("expected %q in %s", line, out)
+			}
+		}
+
+		if _, err = govc(t, c, "device.info", "-vm", "VM0", "enoent"); err == nil {
+			t.Error("expected an error with a missing device")
+		}
+	})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govc sources https://github.com/vmware/govmomi/govc
+*/
+
+package disk
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type attach struct {
+	*flags.VirtualMachineFlag
+
+	controller string
+	unit       int
+}
+
+func init() {
+	cli.Register("device.disk.attach", &attach{})
+}
+
+func (cmd *attach) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.controller, "controller", "", "Disk controller")
+	f.IntVar(&cmd.unit, "unit", -1, "Unit number on the disk controller (first free unit if not set)")
+}
+
+func (cmd *attach) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *attach) Usage() string {
+	return "DISK"
+}
+
+func (cmd *attach) Description() string {
+	return `Attach the existing disk file DISK to VM, which must be powered off.
+
+The -controller is scsi, sata, nvme, ide or the name of a disk controller of the VM.
+The disk is used in place, no child disk is created.
+
+Examples:
+  govc device.disk.attach -vm $name ~/vmware/disks/data.vmdk
+  govc device.disk.attach -vm $name -controller sata ~/vmware/disks/data.vmdk
+  govc device.disk.attach -vm $name -controller nvme-20000 -unit 2 ~/vmware/disks/data.vmdk`
+}
+
+func (cmd *attach) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return flag.ErrHelp
+	}
+
+	name, err := filepath.Abs(f.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller, err := devices.FindDiskController(cmd.controller)
+	if err != nil {
+		return err
+	}
+
+	return vm.AttachDisk(ctx, name, controller.GetVirtualController().Key, int32(cmd.unit))
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"context"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type detach struct {
+	*flags.VirtualMachineFlag
+}
+
+func init() {
+	cli.Register("device.disk.detach", &detach{})
+}
+
+func (cmd *detach) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+}
+
+func (cmd *detach) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *detach) Usage() string {
+	return "DISK..."
+}
+
+func (cmd *detach) Description() string {
+	return `Detach the disk devices DISK from VM, which must be powered off.
+
+The disk files are kept.
+
+Examples:
+  govc device.disk.detach -vm $name disk-1000-1
+  govc device.ls -vm $name disk-* # find the disk device names`
+}
+
+func (cmd *detach) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() == 0 {
+		return flag.ErrHelp
+	}
+
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return flag.ErrHelp
+	}
+
+	for _, name := range f.Args() {
+		if err = vm.DetachDisk(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+func TestAttachDetach(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "VM0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir := t.TempDir()
+		data := filepath.Join(dir, "data.vmdk")
+		logs := filepath.Join(dir, "logs.vmdk")
+
+		for _, name := range []string{data, logs} {
+			if err = vmdk.Create(name, vmdk.MonolithicSparse, 1<<30, "lsilogic"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err = govc(t, c, "device.disk.attach", "-vm", "VM0", data); err == nil {
+			t.Error("expected an error attaching a disk to a powered on VM")
+		}
+
+		task, err := vm.PowerOff(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = task.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			args []string
+			node string
+			disk string
+		}{
+			{[]string{data}, "scsi0:1", data},
+			{[]string{"-controller", "lsilogic-1000", "-unit", "3", logs}, "scsi0:3", logs},
+		}
+
+		for _, test := range tests {
+			if _, err = govc(t, c, append([]string{"device.disk.attach", "-vm", "VM0"}, test.args...)...); err != nil {
+				t.Fatalf("%v: %s", test.args, err)
+			}
+
+			cfg, err := vmx.Load(vmxPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.Get(test.node+".fileName") != test.disk {
+				t.Errorf("%v: expected %s on %s, got %q", test.args, test.disk, test.node, cfg.Get(test.node+".fileName"))
+			}
+		}
+
+		for _, args := range [][]string{
+			{filepath.Join(dir, "enoent.vmdk")},
+			{"-controller", "nvme", data},
+			{"-controller", "lsilogic-1000", "-unit", "3", data},
+		} {
+			if _, err = govc(t, c, append([]string{"device.disk.attach", "-vm", "VM0"}, args...)...); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		}
+
+		if _, err = govc(t, c, "device.disk.detach", "-vm", "VM0", "disk-1000-1", "disk-1000-3"); err != nil {
+			t.Fatal(err)
+		}
+
+		devices, err := vm.Device(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if disks := devices.SelectByType((*types.VirtualDisk)(nil)); len(disks) != 1 {
+			t.Errorf("expected 1 disk, got %d", len(disks))
+		}
+
+		for _, name := range []string{data, logs} {
+			if _, err = os.Stat(name); err != nil {
+				t.Errorf("expected the disk file to be kept: %s", err)
+			}
+		}
+
+		for _, name := range []string{"disk-1000-1", "ethernet-0"} {
+			if _, err = govc(t, c, "device.disk.detach", "-vm", "VM0", name); err == nil {
+				t.Errorf("%s: expected an error detaching a device which is not an attached disk", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govc sources https://github.com/vmware/govmomi/govc
+*/
+
+package device
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type ls struct {
+	*flags.VirtualMachineFlag
+	*flags.OutputFlag
+}
+
+func init() {
+	cli.Register("device.ls", &ls{})
+}
+
+func (cmd *ls) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+}
+
+func (cmd *ls) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *ls) Usage() string {
+	return "[DEVICE]..."
+}
+
+func (cmd *ls) Description() string {
+	return `List devices for VM.
+
+The devices are read from the VM vmx file, which must be reachable from this host.
+
+Examples:
+  govc device.ls -vm $name
+  govc device.ls -vm $name disk-*
+  govc device.ls -vm $name -json | jq '.Devices[].Name'`
+}
+
+func (cmd *ls) Run(ctx context.Context, f *flag.FlagSet) error {
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return flag.ErrHelp
+	}
+
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return err
+	}
+
+	if f.NArg() != 0 {
+		var matches object.VirtualDeviceList
+		for _, name := range f.Args() {
+			device := match(name, devices)
+			if len(device) == 0 {
+				return fmt.Errorf("device '%s' not found", name)
+			}
+			matches = append(matches, device...)
+		}
+		devices = matches
+	}
+
+	res := lsResult{toLsList(devices), devices}
+	return cmd.WriteResult(&res)
+}
+
+type lsDevice struct {
+	Name    string
+	Type    string
+	Summary string
+}
+
+func toLsList(devices object.VirtualDeviceList) []lsDevice {
+	var res []lsDevice
+
+	for _, device := range devices {
+		res = append(res, lsDevice{
+			Name:    devices.Name(device),
+			Type:    devices.TypeName(device),
+			Summary: device.GetVirtualDevice().DeviceInfo.GetDescription().Summary,
+		})
+	}
+
+	return res
+}
+
+type lsResult struct {
+	Devices []lsDevice
+	list    object.VirtualDeviceList
+}
+
+func (r *lsResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 3, 0, 2, ' ', 0)
+
+	for _, device := range r.list {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.list.Name(device), r.list.TypeName(device),
+			device.GetVirtualDevice().DeviceInfo.GetDescription().Summary)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/units"
+)
+
+type create struct {
+	size        units.ByteSize
+	diskType    string
+	adapterType string
+}
+
+func init() {
+	cli.Register("disk.create", &create{})
+}
+
+func (cmd *create) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.size = 10 * units.GB
+	f.Var(&cmd.size, "size", "Size of new disk")
+	f.StringVar(&cmd.diskType, "type", vmdk.MonolithicSparse, fmt.Sprintf("Disk type (%s)", strings.Join(vmdk.Types, "|")))
+	f.StringVar(&cmd.adapterType, "a", vmdk.AdapterTypes[0], fmt.Sprintf("Disk adapter type (%s)", strings.Join(vmdk.AdapterTypes, "|")))
+}
+
+func (cmd *create) Process(ctx context.Context) error {
+	return nil
+}
+
+func (cmd *create) Usage() string {
+	return "PATH"
+}
+
+func (cmd *create) Description() string {
+	return `Create a virtual disk file.
+
+The disk is created on this host, it is empty and grows as it is written.
+A twoGbMaxExtentSparse disk is split in extent files of less than 4GB next to the PATH descriptor.
+The -a adapter type of a disk attached to a SCSI controller of any type (lsisas1068, pvscsi) or to
+an NVMe controller is lsilogic, a disk attached to a SATA controller is an ide disk.
+
+Examples:
+  govc disk.create -size 20G ~/vmware/disks/data.vmdk
+  govc disk.create -size 100G -type twoGbMaxExtentSparse -a ide ~/vmware/disks/big.vmdk`
+}
+
+func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	name, err := filepath.Abs(f.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if filepath.Ext(name) != ".vmdk" {
+		name += ".vmdk"
+	}
+
+	return vmdk.Create(name, cmd.diskType, int64(cmd.size), cmd.adapterType)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+func diskLs(t *testing.T, c *vim25.Client, args ...string) []diskInfo {
+	t.Helper()
+
+	out, err := govc(t, c, append([]string{"disk.ls", "-json"}, args...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res lsResult
+	if err = json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	return res.Disks
+}
+
+func TestDisk(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		dir := t.TempDir()
+
+		tests := []struct {
+			args   []string
+			expect diskInfo
+		}{
+			{[]string{"-size", "1G", "a.vmdk"}, diskInfo{Capacity: 1 << 30, Type: vmdk.MonolithicSparse, AdapterType: "lsilogic"}},
+			{[]string{"-size", "5G", "-type", vmdk.TwoGbMaxExtentSparse, "-a", "ide", "b.vmdk"},
+				diskInfo{Capacity: 5 << 30, Type: vmdk.TwoGbMaxExtentSparse, AdapterType: "ide"}},
+		}
+
+		for _, test := range tests {
+			n := len(test.args) - 1
+			test.args[n] = filepath.Join(dir, test.args[n])
+			test.expect.Path = test.args[n]
+
+			if _, err := govc(t, c, append([]string{"disk.create"}, test.args...)...); err != nil {
+				t.Fatalf("%v: %s", test.args, err)
+			}
+
+			if disks := diskLs(t, c, test.expect.Path); len(disks) != 1 || fmt.Sprint(disks[0]) != fmt.Sprint(test.expect) {
+				t.Errorf("%v: expected %+v, got %+v", test.args, test.expect, disks)
+			}
+		}
+
+		// the extent files of b.vmdk are not listed
+		if disks := diskLs(t, c, dir); len(disks) != 2 {
+			t.Errorf("expected 2 disks, got %+v", disks)
+		}
+
+		for _, args := range [][]string{
+			{"-size", "1G", filepath.Join(dir, "a.vmdk")},
+			{"-size", "1G", "-a", "pvscsi", filepath.Join(dir, "c.vmdk")},
+			{"-size", "1G", "-type", "monolithicFlat", filepath.Join(dir, "c.vmdk")},
+		} {
+			if _, err := govc(t, c, append([]string{"disk.create"}, args...)...); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		}
+
+		a := filepath.Join(dir, "a.vmdk")
+
+		if _, err := govc(t, c, "disk.extend", "-size", "2G", a); err != nil {
+			t.Fatal(err)
+		}
+
+		if disks := diskLs(t, c, a); disks[0].Capacity != 2<<30 {
+			t.Errorf("expected a 2GB disk, got %+v", disks[0])
+		}
+
+		if _, err := govc(t, c, "disk.extend", "-size", "1G", a); err == nil {
+			t.Error("expected an error shrinking a disk")
+		}
+
+		// the parents of a child disk are listed
+		child := filepath.Join(dir, "a-cl1.vmdk")
+		if err := vmdk.CreateChild(child, "a.vmdk"); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := govc(t, c, "disk.ls", "-l", child)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.Contains(lines[0], "lsilogic") || strings.TrimSpace(lines[1]) != a {
+			t.Errorf("unexpected output: %s", out)
+		}
+
+		if _, err = govc(t, c, "disk.extend", "-size", "4G", a); err == nil {
+			t.Error("expected an error extending a disk with child disks")
+		}
+
+		// the disk of a VM, which must be powered off
+		if _, err = govc(t, c, "disk.extend", "-vm", "VM0", "-size", "20G", "disk-1000-0"); err == nil {
+			t.Error("expected an error extending the disk of a powered on VM")
+		}
+
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "VM0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		task, err := vm.PowerOff(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = task.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "disk.extend", "-vm", "VM0", "-size", "20G", "disk-1000-0"); err != nil {
+			t.Fatal(err)
+		}
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if disks := diskLs(t, c, filepath.Join(filepath.Dir(vmxPath), "VM0.vmdk")); disks[0].Capacity != 20<<30 {
+			t.Errorf("expected a 20GB disk, got %+v", disks[0])
+		}
+
+		if _, err = govc(t, c, "disk.extend", "-vm", "VM0", "-size", "30G", "disk-1000-1"); err == nil {
+			t.Error("expected an error extending a missing disk")
+		}
+	})
+}
@@ -0,0 +1,103 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/units"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type extend struct {
+	*flags.VirtualMachineFlag
+
+	size units.ByteSize
+}
+
+func init() {
+	cli.Register("disk.extend", &extend{})
+}
+
+func (cmd *extend) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	f.Var(&cmd.size, "size", "New size of the disk")
+}
+
+func (cmd *extend) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *extend) Usage() string {
+	return "DISK"
+}
+
+func (cmd *extend) Description() string {
+	return `Extend the virtual disk DISK to the -size capacity.
+
+DISK is the path of a disk file, or the name of a disk device of the -vm VM, which must be powered off.
+The disk must not be in use. A child disk such as a snapshot disk cannot be extended, neither can
+a disk with child disks, such as the base disk of linked clones, nor the disks of a VM with linked clones.
+The guest partitions are not resized.
+
+Examples:
+  govc disk.extend -size 40G ~/vmware/disks/data.vmdk
+  govc disk.extend -vm $name -size 40G disk-1000-0`
+}
+
+func (cmd *extend) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 || cmd.size == 0 {
+		return flag.ErrHelp
+	}
+
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		name, err := filepath.Abs(f.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		return vmdk.Extend(name, int64(cmd.size))
+	}
+
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return err
+	}
+
+	disk, ok := devices.Find(f.Arg(0)).(*types.VirtualDisk)
+	if !ok {
+		return fmt.Errorf("disk '%s' not found", f.Arg(0))
+	}
+
+	return vm.ExtendDisk(ctx, disk, int64(cmd.size))
+}
@@ -0,0 +1,186 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package disk
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/units"
+)
+
+type ls struct {
+	*flags.OutputFlag
+
+	long bool
+}
+
+func init() {
+	cli.Register("disk.ls", &ls{})
+}
+
+func (cmd *ls) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.BoolVar(&cmd.long, "l", false, "Long listing format")
+}
+
+func (cmd *ls) Process(ctx context.Context) error {
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *ls) Usage() string {
+	return "PATH..."
+}
+
+func (cmd *ls) Description() string {
+	return `List the virtual disk files.
+
+PATH is a disk descriptor or a directory, the disks of a directory are listed.
+The long listing format includes the adapter type and the parent disks of a child disk.
+
+Examples:
+  govc disk.ls ~/vmware/ubuntu
+  govc disk.ls -l ~/vmware/ubuntu/ubuntu-000001.vmdk
+  govc disk.ls -json ~/vmware/ubuntu | jq -r '.Disks[] | select(.Parents != null) | .Path'`
+}
+
+type diskInfo struct {
+	Path        string
+	Capacity    int64
+	Type        string
+	AdapterType string
+	Parents     []string
+}
+
+func info(name string) (*diskInfo, error) {
+	d, err := vmdk.Read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	parents, err := vmdk.Parents(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &diskInfo{
+		Path:        name,
+		Capacity:    d.Capacity(),
+		Type:        d.CreateType,
+		AdapterType: d.AdapterType(),
+		Parents:     parents,
+	}, nil
+}
+
+// disks returns the disks of a directory, the extent files and the unreadable files are skipped
+func disks(dir string) ([]diskInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []diskInfo
+
+	for _, e := range entries {
+		if e.IsDir() || !strings.EqualFold(filepath.Ext(e.Name()), ".vmdk") {
+			continue
+		}
+
+		if d, err := info(filepath.Join(dir, e.Name())); err == nil {
+			res = append(res, *d)
+		}
+	}
+
+	return res, nil
+}
+
+func (cmd *ls) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() == 0 {
+		return flag.ErrHelp
+	}
+
+	res := lsResult{long: cmd.long}
+
+	for _, arg := range f.Args() {
+		name, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+
+		s, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+
+		if s.IsDir() {
+			list, err := disks(name)
+			if err != nil {
+				return err
+			}
+
+			res.Disks = append(res.Disks, list...)
+			continue
+		}
+
+		d, err := info(name)
+		if err != nil {
+			return err
+		}
+
+		res.Disks = append(res.Disks, *d)
+	}
+
+	return cmd.WriteResult(&res)
+}
+
+type lsResult struct {
+	Disks []diskInfo
+	long  bool
+}
+
+func (r *lsResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 3, 0, 2, ' ', 0)
+
+	for _, d := range r.Disks {
+		if !r.long {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", d.Path, units.ByteSize(d.Capacity), d.Type)
+			continue
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", d.Path, units.ByteSize(d.Capacity), d.Type, d.AdapterType)
+
+		for _, parent := range d.Parents {
+			fmt.Fprintf(tw, "  %s\t\t\t\n", parent)
+		}
+	}
+
+	return tw.Flush()
+}
@@ -20,9 +20,13 @@ package find
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"strings"
 
 	"github.com/Fred78290/govmrest/object"
 	"github.com/Fred78290/govmrest/vim25"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 type Finder struct {
@@ -38,8 +42,61 @@ func NewFinder(client *vim25.Client, all ...bool) *Finder {
 	return f
 }
 
-func (f *Finder) VirtualMachineList(ctx context.Context, path string) ([]*object.VirtualMachine, error) {
-	return nil, nil
+// match reports whether name matches the shell pattern, an exact name always matches
+// even if it contains pattern meta characters.
+func match(pattern, name string) (bool, error) {
+	if pattern == name {
+		return true, nil
+	}
+
+	return path.Match(pattern, name)
+}
+
+// VirtualMachineList returns the virtual machines matching the given path.
+// A path containing a '/' is matched against the inventory path or the .vmx path,
+// otherwise it is matched against the virtual machine name. Glob patterns are supported.
+func (f *Finder) VirtualMachineList(ctx context.Context, arg string) ([]*object.VirtualMachine, error) {
+	vmids, err := f.client.GetAllVMs()
+	if err != nil {
+		return nil, err
+	}
+
+	isPath := strings.Contains(arg, "/")
+
+	var vms []*object.VirtualMachine
+
+	for _, vmid := range vmids {
+		var ok bool
+
+		vm := object.NewVirtualMachine(f.client, types.ManagedObjectReference{
+			Type:  "VirtualMachine",
+			Value: vmid.Id,
+		})
+
+		vm.InventoryPath = object.InventoryPath(vmid.Path)
+
+		if isPath {
+			ok = arg == vmid.Path
+
+			if !ok {
+				if ok, err = match(arg, vm.InventoryPath); err != nil {
+					return nil, err
+				}
+			}
+		} else if ok, err = match(arg, vm.Name()); err != nil {
+			return nil, err
+		}
+
+		if ok {
+			vms = append(vms, vm)
+		}
+	}
+
+	if len(vms) == 0 {
+		return nil, &NotFoundError{"vm", arg}
+	}
+
+	return vms, nil
 }
 
 func (f *Finder) VirtualMachine(ctx context.Context, path string) (*object.VirtualMachine, error) {
@@ -54,3 +111,85 @@ func (f *Finder) VirtualMachine(ctx context.Context, path string) (*object.Virtu
 
 	return vms[0], nil
 }
+
+// NetworkList returns the virtual networks whose vmnet name matches the given glob pattern.
+func (f *Finder) NetworkList(ctx context.Context, arg string) ([]*object.Network, error) {
+	networks, err := f.client.GetAllNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := strings.TrimPrefix(arg, "/")
+
+	var nets []*object.Network
+
+	for _, network := range networks.Vmnets {
+		ok, err := match(pattern, network.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			net := object.NewNetwork(f.client, types.ManagedObjectReference{
+				Type:  "Network",
+				Value: network.Name,
+			})
+
+			net.InventoryPath = object.NetworkInventoryPath(network.Name)
+
+			nets = append(nets, net)
+		}
+	}
+
+	if len(nets) == 0 {
+		return nil, &NotFoundError{"network", arg}
+	}
+
+	return nets, nil
+}
+
+func (f *Finder) Network(ctx context.Context, path string) (*object.Network, error) {
+	nets, err := f.NetworkList(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nets) > 1 {
+		return nil, &MultipleFoundError{"network", path}
+	}
+
+	return nets[0], nil
+}
+
+// ObjectReference converts the given ManagedObjectReference to a type from the object package,
+// with the InventoryPath field set.
+func (f *Finder) ObjectReference(ctx context.Context, ref types.ManagedObjectReference) (object.Reference, error) {
+	switch ref.Type {
+	case "VirtualMachine":
+	case "Network":
+		net, err := f.Network(ctx, ref.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return net, nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", ref.Type)
+	}
+
+	vmids, err := f.client.GetAllVMs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vmid := range vmids {
+		if vmid.Id == ref.Value {
+			vm := object.NewVirtualMachine(f.client, ref)
+			vm.InventoryPath = object.InventoryPath(vmid.Path)
+
+			return vm, nil
+		}
+	}
+
+	return nil, &NotFoundError{"vm", ref.Value}
+}
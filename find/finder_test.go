@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package find_test
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+)
+
+func TestVirtualMachineList(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		finder := find.NewFinder(c)
+
+		tests := []struct {
+			path  string
+			names []string
+		}{
+			{"*", []string{"VM0", "VM1"}},
+			{"VM*", []string{"VM0", "VM1"}},
+			{"VM1", []string{"VM1"}},
+			{"VM[0]", []string{"VM0"}},
+		}
+
+		for _, test := range tests {
+			vms, err := finder.VirtualMachineList(ctx, test.path)
+			if err != nil {
+				t.Fatalf("%s: %s", test.path, err)
+			}
+
+			if len(vms) != len(test.names) {
+				t.Fatalf("%s: expected %d vms, got %d", test.path, len(test.names), len(vms))
+			}
+
+			for i, vm := range vms {
+				if vm.Name() != test.names[i] {
+					t.Errorf("%s: expected %s, got %s", test.path, test.names[i], vm.Name())
+				}
+			}
+		}
+
+		vm, err := finder.VirtualMachine(ctx, "VM0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the vmx path is matched as is, an inventory path as a pattern
+		for _, p := range []string{vmxPath, vm.InventoryPath, path.Join(path.Dir(path.Dir(vm.InventoryPath)), "*", "VM0")} {
+			vms, err := finder.VirtualMachineList(ctx, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(vms) != 1 || vms[0].Reference() != vm.Reference() {
+				t.Errorf("%s: expected %s", p, vm.Reference())
+			}
+		}
+
+		var notFound *find.NotFoundError
+
+		if _, err = finder.VirtualMachineList(ctx, "enoent"); !errors.As(err, &notFound) {
+			t.Errorf("expected NotFoundError, got %v", err)
+		}
+
+		var multiple *find.MultipleFoundError
+
+		if _, err = finder.VirtualMachine(ctx, "VM*"); !errors.As(err, &multiple) {
+			t.Errorf("expected MultipleFoundError, got %v", err)
+		}
+	})
+}
+
+func TestNetworkList(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		finder := find.NewFinder(c)
+
+		tests := []struct {
+			path  string
+			names []string
+		}{
+			{"*", []string{"vmnet0", "vmnet1", "vmnet8"}},
+			{"vmnet[18]", []string{"vmnet1", "vmnet8"}},
+			{"vmnet8", []string{"vmnet8"}},
+			{"/vmnet8", []string{"vmnet8"}},
+		}
+
+		for _, test := range tests {
+			nets, err := finder.NetworkList(ctx, test.path)
+			if err != nil {
+				t.Fatalf("%s: %s", test.path, err)
+			}
+
+			if len(nets) != len(test.names) {
+				t.Fatalf("%s: expected %d networks, got %d", test.path, len(test.names), len(nets))
+			}
+
+			for i, net := range nets {
+				if net.Name() != test.names[i] {
+					t.Errorf("%s: expected %s, got %s", test.path, test.names[i], net.Name())
+				}
+			}
+		}
+
+		var notFound *find.NotFoundError
+
+		if _, err := finder.NetworkList(ctx, "vmnet9"); !errors.As(err, &notFound) {
+			t.Errorf("expected NotFoundError, got %v", err)
+		}
+
+		var multiple *find.MultipleFoundError
+
+		if _, err := finder.Network(ctx, "*"); !errors.As(err, &multiple) {
+			t.Errorf("expected MultipleFoundError, got %v", err)
+		}
+	})
+}
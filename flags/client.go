@@ -75,7 +75,7 @@ func NewClientFlag(ctx context.Context) (*ClientFlag, context.Context) {
 	u, _ := url.Parse("https://localhost:8697")
 	v := &ClientFlag{
 		endpoint: u,
-		timeout:  120,
+		timeout:  120 * time.Second,
 	}
 	v.DebugFlag, ctx = flags.NewDebugFlag(ctx)
 	ctx = context.WithValue(ctx, clientFlagKey, v)
@@ -157,22 +157,15 @@ func (flag *ClientFlag) Client() (*vim25.Client, error) {
 		return flag.client, nil
 	}
 
-	cfg := &client.Configuration{
-		Endpoint:  flag.endpoint.String(),
-		UserAgent: "govmrest/1.0.0/go",
-		UserName:  flag.username,
-		Password:  flag.password,
-		Timeout:   120,
-	}
+	rc := vim25.NewRESTClient(flag.endpoint, flag.timeout)
 
-	if client, err := client.NewAPIClient(cfg); err != nil {
+	client, err := vim25.NewClient(context.Background(), &client.APIClient{Client: rc})
+	if err != nil {
 		return nil, err
-	} else {
-		flag.client = &vim25.Client{
-			APIClient: client,
-		}
 	}
 
+	flag.client = client
+
 	return flag.client, nil
 }
 
@@ -0,0 +1,101 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govc sources https://github.com/vmware/govmomi/govc
+*/
+
+package flags
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Fred78290/govmrest/object"
+)
+
+type NetworkFlag struct {
+	common
+
+	name    string
+	adapter string
+	address string
+	isset   bool
+}
+
+var networkFlagKey = flagKey("network")
+
+func NewNetworkFlag(ctx context.Context) (*NetworkFlag, context.Context) {
+	if v := ctx.Value(networkFlagKey); v != nil {
+		return v.(*NetworkFlag), ctx
+	}
+
+	v := &NetworkFlag{}
+	ctx = context.WithValue(ctx, networkFlagKey, v)
+	return v, ctx
+}
+
+func (flag *NetworkFlag) Register(ctx context.Context, f *flag.FlagSet) {
+	flag.RegisterOnce(func() {
+		env := "GOVMREST_NETWORK"
+		value := os.Getenv(env)
+		flag.name = value
+		usage := fmt.Sprintf("Network, bridged|nat|hostonly or a custom vmnet [%s]", env)
+		f.Var(flag, "net", usage)
+		f.StringVar(&flag.adapter, "net.adapter", "", fmt.Sprintf("Network adapter type (%s)", strings.Join(object.NetworkAdapterDevices, "|")))
+		f.StringVar(&flag.address, "net.address", "", "Network hardware address, '-' for a generated address")
+	})
+}
+
+func (flag *NetworkFlag) Process(ctx context.Context) error {
+	return flag.ProcessOnce(func() error {
+		return nil
+	})
+}
+
+func (flag *NetworkFlag) String() string {
+	return flag.name
+}
+
+func (flag *NetworkFlag) Set(name string) error {
+	flag.name = name
+	flag.isset = true
+	return nil
+}
+
+func (flag *NetworkFlag) IsSet() bool {
+	return flag.isset
+}
+
+// Spec returns the network adapter spec, a network other than bridged, nat or hostonly is a custom vmnet
+func (flag *NetworkFlag) Spec() object.NetworkAdapterSpec {
+	spec := object.NetworkAdapterSpec{
+		VirtualDev: flag.adapter,
+		MacAddress: flag.address,
+	}
+
+	switch name := strings.ToLower(flag.name); name {
+	case "":
+	case "bridged", "nat", "hostonly":
+		spec.Type = name
+	default:
+		spec.Type = "custom"
+		spec.Vmnet = flag.name
+	}
+
+	return spec
+}
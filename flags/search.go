@@ -44,10 +44,12 @@ type SearchFlag struct {
 	finder          *find.Finder
 	byDatastorePath string
 	byDNSName       string
+	byInventoryPath string
 	byIP            string
 	byUUID          string
 
 	isset bool
+	err   error
 }
 
 func NewSearchFlag(ctx context.Context, t int) (*SearchFlag, context.Context) {
@@ -61,6 +63,14 @@ func NewSearchFlag(ctx context.Context, t int) (*SearchFlag, context.Context) {
 		t: t,
 	}
 
+	// the entity names the search flags, vm.path for instance, an unknown type is reported by Process
+	switch t {
+	case SearchVirtualMachines:
+		v.entity = "VM"
+	default:
+		v.err = fmt.Errorf("invalid search type %d", t)
+	}
+
 	v.ClientFlag, ctx = NewClientFlag(ctx)
 
 	ctx = context.WithValue(ctx, searchFlagKey, v)
@@ -71,6 +81,10 @@ func (flag *SearchFlag) Register(ctx context.Context, fs *flag.FlagSet) {
 	flag.RegisterOnce(func() {
 		flag.ClientFlag.Register(ctx, fs)
 
+		if flag.err != nil {
+			return
+		}
+
 		register := func(v *string, f string, d string) {
 			f = fmt.Sprintf("%s.%s", strings.ToLower(flag.entity), f)
 			d = fmt.Sprintf(d, flag.entity)
@@ -79,6 +93,7 @@ func (flag *SearchFlag) Register(ctx context.Context, fs *flag.FlagSet) {
 
 		register(&flag.byDatastorePath, "path", "Find %s by path to .vmx file")
 		register(&flag.byDNSName, "dns", "Find %s by FQDN")
+		register(&flag.byInventoryPath, "ipath", "Find %s by name or inventory path")
 		register(&flag.byIP, "ip", "Find %s by IP address")
 		register(&flag.byUUID, "uuid", "Find %s by UUID")
 	})
@@ -86,6 +101,10 @@ func (flag *SearchFlag) Register(ctx context.Context, fs *flag.FlagSet) {
 
 func (flag *SearchFlag) Process(ctx context.Context) error {
 	return flag.ProcessOnce(func() error {
+		if flag.err != nil {
+			return flag.err
+		}
+
 		if err := flag.ClientFlag.Process(ctx); err != nil {
 			return err
 		}
@@ -93,6 +112,7 @@ func (flag *SearchFlag) Process(ctx context.Context) error {
 		flags := []string{
 			flag.byDatastorePath,
 			flag.byDNSName,
+			flag.byInventoryPath,
 			flag.byIP,
 			flag.byUUID,
 		}
@@ -131,6 +151,17 @@ func (flag *SearchFlag) searchByDNSName(c *vim25.Client) (object.Reference, erro
 	return flag.searchIndex(c).FindByDnsName(ctx, flag.byDNSName)
 }
 
+func (flag *SearchFlag) searchByInventoryPath(c *vim25.Client) (object.Reference, error) {
+	ctx := context.TODO()
+
+	finder, err := flag.Finder()
+	if err != nil {
+		return nil, err
+	}
+
+	return finder.VirtualMachine(ctx, flag.byInventoryPath)
+}
+
 func (flag *SearchFlag) searchByIP(c *vim25.Client) (object.Reference, error) {
 	ctx := context.TODO()
 
@@ -157,6 +188,8 @@ func (flag *SearchFlag) search() (object.Reference, error) {
 		ref, err = flag.searchByDatastorePath(c)
 	case flag.byDNSName != "":
 		ref, err = flag.searchByDNSName(c)
+	case flag.byInventoryPath != "":
+		ref, err = flag.searchByInventoryPath(c)
 	case flag.byIP != "":
 		ref, err = flag.searchByIP(c)
 	case flag.byUUID != "":
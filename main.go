@@ -3,7 +3,15 @@ package main
 import (
 	"os"
 
+	_ "github.com/Fred78290/govmrest/device"
+	_ "github.com/Fred78290/govmrest/device/disk"
+	_ "github.com/Fred78290/govmrest/disk"
+	_ "github.com/Fred78290/govmrest/network"
+	_ "github.com/Fred78290/govmrest/network/dhcp"
+	_ "github.com/Fred78290/govmrest/network/portforward"
 	_ "github.com/Fred78290/govmrest/vm"
+	_ "github.com/Fred78290/govmrest/vm/network"
+	_ "github.com/Fred78290/govmrest/vm/sharedfolder"
 	"github.com/vmware/govmomi/govc/cli"
 )
 
@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"flag"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type change struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+
+	spec object.NetworkSpec
+}
+
+func init() {
+	cli.Register("network.change", &change{})
+}
+
+func (cmd *change) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	registerSpec(f, &cmd.spec)
+}
+
+func (cmd *change) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *change) Usage() string {
+	return "NAME"
+}
+
+func (cmd *change) Description() string {
+	return `Change the settings of the host-only or NAT virtual network NAME.
+
+Settings that are not specified are left unchanged, the subnet must not overlap
+the subnet of another virtual network.
+
+Examples:
+  govc network.change -subnet 192.168.130.0/24 vmnet2
+  govc network.change -type nat vmnet2
+  govc network.change -dhcp=false vmnet2`
+}
+
+func (cmd *change) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	c, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	net, err := find.NewFinder(c).Network(ctx, f.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err = net.Change(ctx, cmd.spec); err != nil {
+		return err
+	}
+
+	info, err := net.Info(ctx)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&infoResult{Networks: []model.Network{*info}})
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type create struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+
+	spec object.NetworkSpec
+}
+
+func init() {
+	cli.Register("network.create", &create{})
+}
+
+// registerSpec registers the flags of the network settings
+func registerSpec(f *flag.FlagSet, spec *object.NetworkSpec) {
+	f.StringVar(&spec.Type, "type", "", fmt.Sprintf("Network type (%s)", strings.Join(object.NetworkTypes, "|")))
+	f.StringVar(&spec.Subnet, "subnet", "", "Subnet network address or CIDR")
+	f.StringVar(&spec.Mask, "mask", "", "Subnet mask")
+	f.Var(flags.NewOptionalBool(&spec.DHCP), "dhcp", "Enable the DHCP server")
+}
+
+func (cmd *create) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	registerSpec(f, &cmd.spec)
+}
+
+func (cmd *create) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *create) Usage() string {
+	return "NAME"
+}
+
+func (cmd *create) Description() string {
+	return `Create the host-only or NAT virtual network NAME.
+
+NAME is a vmnetN name, the network is host-only unless '-type nat' is specified.
+The subnet defaults to one chosen by vmrest and must not overlap the subnet
+of another virtual network.
+
+Examples:
+  govc network.create vmnet2
+  govc network.create -type nat -subnet 192.168.120.0/24 vmnet3
+  govc network.create -subnet 10.10.0.0 -mask 255.255.0.0 -dhcp=false vmnet4`
+}
+
+func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	c, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	net, err := object.CreateNetwork(ctx, c, f.Arg(0), cmd.spec)
+	if err != nil {
+		return err
+	}
+
+	info, err := net.Info(ctx)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&infoResult{Networks: []model.Network{*info}})
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+// reservations returns the reservations of vmnet8 as "mac ip"
+func reservations(t *testing.T, c *vim25.Client) []string {
+	t.Helper()
+
+	out, err := govc(t, c, "network.dhcp.ls", "-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res lsResult
+	if err = json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	var list []string
+	for _, reservation := range res.Reservations {
+		list = append(list, reservation.Mac+" "+reservation.Ip)
+	}
+
+	return list
+}
+
+func TestDHCP(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		finder := find.NewFinder(c)
+
+		vmnet8, err := finder.Network(ctx, "vmnet8")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := vmnet8.Info(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ip := func(n byte) string {
+			ip := net.ParseIP(info.Subnet).To4()
+			ip[3] = n
+			return ip.String()
+		}
+
+		vm, err := finder.VirtualMachine(ctx, "VM0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		devices, err := vm.Device(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mac := devices.PrimaryMacAddress()
+
+		for _, args := range [][]string{
+			{"00:50:56:2a:10:01", ip(10)},
+			{"00:50:56:2a:10:01", ip(11)}, // replaces the reservation
+			{"00:50:56:2A:10:01", ip(11)}, // unchanged
+			{"-vm", "VM0", ip(12)},
+		} {
+			if _, err = govc(t, c, append([]string{"network.dhcp.set"}, args...)...); err != nil {
+				t.Fatalf("%v: %s", args, err)
+			}
+		}
+
+		expect := []string{mac + " " + ip(12), "00:50:56:2a:10:01 " + ip(11)}
+		if mac > "00:50:56:2a:10:01" {
+			expect[0], expect[1] = expect[1], expect[0]
+		}
+
+		if list := reservations(t, c); fmt.Sprint(list) != fmt.Sprint(expect) {
+			t.Errorf("expected %v, got %v", expect, list)
+		}
+
+		for _, args := range [][]string{
+			{"00:50:56:2a:10:02", ip(11)},      // reserved for another MAC address
+			{"00:50:56:2a:10:02", "192.0.2.1"}, // not on the subnet
+			{"00:50:56:2a:10:02", "enoent"},
+			{"enoent", ip(13)},
+			{"-vmnet", "vmnet0", "00:50:56:2a:10:02", "192.168.0.13"},
+			{ip(13)},
+		} {
+			if _, err = govc(t, c, append([]string{"network.dhcp.set"}, args...)...); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		}
+
+		if list := reservations(t, c); fmt.Sprint(list) != fmt.Sprint(expect) {
+			t.Errorf("expected %v, got %v", expect, list)
+		}
+
+		out, err := govc(t, c, "network.dhcp.ls")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out, mac) || !strings.Contains(out, ip(11)) {
+			t.Errorf("unexpected output: %s", out)
+		}
+
+		for _, args := range [][]string{
+			{"00:50:56:2A:10:01"},
+			{"-vm", "VM0"},
+		} {
+			if _, err = govc(t, c, append([]string{"network.dhcp.remove"}, args...)...); err != nil {
+				t.Fatalf("%v: %s", args, err)
+			}
+		}
+
+		for _, args := range [][]string{
+			{"00:50:56:2a:10:01"}, // not reserved
+			{"enoent"},
+			{},
+		} {
+			if _, err = govc(t, c, append([]string{"network.dhcp.remove"}, args...)...); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		}
+
+		if list := reservations(t, c); len(list) != 0 {
+			t.Errorf("expected no reservation, got %v", list)
+		}
+	})
+}
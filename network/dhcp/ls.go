@@ -0,0 +1,134 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// defaultVmnet is the network used when neither a vmnet nor a VM is specified
+const defaultVmnet = "vmnet8"
+
+type ls struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+
+	vmnet string
+}
+
+func init() {
+	cli.Register("network.dhcp.ls", &ls{})
+}
+
+func (cmd *ls) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.vmnet, "vmnet", defaultVmnet, "Virtual network")
+}
+
+func (cmd *ls) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *ls) Description() string {
+	return `List the MAC to IP reservations of a virtual network DHCP server.
+
+Examples:
+  govc network.dhcp.ls
+  govc network.dhcp.ls -vmnet vmnet1 -json`
+}
+
+// network returns the virtual network named vmnet
+func network(ctx context.Context, c *flags.ClientFlag, vmnet string) (*object.Network, error) {
+	client, err := c.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	return find.NewFinder(client).Network(ctx, vmnet)
+}
+
+// primaryNIC returns the MAC address and the network of the VM primary network adapter
+func primaryNIC(ctx context.Context, vm *object.VirtualMachine) (string, string, error) {
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	mac := devices.PrimaryMacAddress()
+	if mac == "" {
+		return "", "", fmt.Errorf("%s has no network adapter with a MAC address", vm.Name())
+	}
+
+	var vmnet string
+
+	backing := devices.Find("ethernet-0").GetVirtualDevice().Backing
+	if backing, ok := backing.(*types.VirtualEthernetCardNetworkBackingInfo); ok {
+		vmnet = backing.DeviceName
+	}
+
+	return mac, vmnet, nil
+}
+
+func (cmd *ls) Run(ctx context.Context, f *flag.FlagSet) error {
+	net, err := network(ctx, cmd.ClientFlag, cmd.vmnet)
+	if err != nil {
+		return err
+	}
+
+	reservations, err := net.DHCPReservations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&lsResult{Reservations: reservations})
+}
+
+type lsResult struct {
+	Reservations []model.MactoIp
+}
+
+func (r *lsResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 3, 0, 2, ' ', 0)
+
+	for _, reservation := range r.Reservations {
+		fmt.Fprintf(tw, "%s\t%s\n", reservation.Mac, reservation.Ip)
+	}
+
+	return tw.Flush()
+}
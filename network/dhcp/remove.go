@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"context"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type remove struct {
+	*flags.VirtualMachineFlag
+
+	vmnet string
+}
+
+func init() {
+	cli.Register("network.dhcp.remove", &remove{})
+}
+
+func (cmd *remove) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.vmnet, "vmnet", "", "Virtual network, defaults to the VM primary network or "+defaultVmnet)
+}
+
+func (cmd *remove) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *remove) Usage() string {
+	return "[MAC]..."
+}
+
+func (cmd *remove) Description() string {
+	return `Remove the IP reservations of the MAC addresses from a virtual network DHCP server.
+
+When a VM is specified the reservation of the VM primary network adapter is removed.
+
+Examples:
+  govc network.dhcp.remove 00:50:56:2a:10:01
+  govc network.dhcp.remove -vm $vm`
+}
+
+func (cmd *remove) Run(ctx context.Context, f *flag.FlagSet) error {
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	macs := f.Args()
+	vmnet := cmd.vmnet
+
+	if vm != nil {
+		mac, nicVmnet, err := primaryNIC(ctx, vm)
+		if err != nil {
+			return err
+		}
+
+		if vmnet == "" {
+			vmnet = nicVmnet
+		}
+
+		macs = append(macs, mac)
+	}
+
+	if len(macs) == 0 {
+		return flag.ErrHelp
+	}
+
+	if vmnet == "" {
+		vmnet = defaultVmnet
+	}
+
+	net, err := network(ctx, cmd.ClientFlag, vmnet)
+	if err != nil {
+		return err
+	}
+
+	for _, mac := range macs {
+		if err = net.RemoveReservation(ctx, mac); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dhcp
+
+import (
+	"context"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type set struct {
+	*flags.VirtualMachineFlag
+
+	vmnet string
+}
+
+func init() {
+	cli.Register("network.dhcp.set", &set{})
+}
+
+func (cmd *set) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.vmnet, "vmnet", "", "Virtual network, defaults to the VM primary network or "+defaultVmnet)
+}
+
+func (cmd *set) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *set) Usage() string {
+	return "[MAC] IP"
+}
+
+func (cmd *set) Description() string {
+	return `Reserve IP for the MAC address in a virtual network DHCP server.
+
+When a VM is specified the MAC argument is omitted and the address of the VM primary
+network adapter is used, the VM files must be reachable from this host. IP must be
+on the network subnet and not reserved for another MAC address, the current
+reservation of the MAC address is replaced.
+
+Examples:
+  govc network.dhcp.set 00:50:56:2a:10:01 192.168.38.10
+  govc network.dhcp.set -vm $vm 192.168.38.11
+  govc vm.clone -vm.ipath template -on=false $name && govc network.dhcp.set -vm $name 192.168.38.12`
+}
+
+func (cmd *set) Run(ctx context.Context, f *flag.FlagSet) error {
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	args := f.Args()
+
+	if vm == nil && len(args) != 2 || vm != nil && len(args) != 1 {
+		return flag.ErrHelp
+	}
+
+	vmnet := cmd.vmnet
+
+	var mac string
+
+	if vm == nil {
+		mac = args[0]
+	} else {
+		var nicVmnet string
+
+		if mac, nicVmnet, err = primaryNIC(ctx, vm); err != nil {
+			return err
+		}
+
+		if vmnet == "" {
+			vmnet = nicVmnet
+		}
+	}
+
+	if vmnet == "" {
+		vmnet = defaultVmnet
+	}
+
+	net, err := network(ctx, cmd.ClientFlag, vmnet)
+	if err != nil {
+		return err
+	}
+
+	return net.ReserveIP(ctx, mac, args[len(args)-1])
+}
@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type info struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+}
+
+func init() {
+	cli.Register("network.info", &info{})
+}
+
+func (cmd *info) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+}
+
+func (cmd *info) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *info) Usage() string {
+	return "NAME..."
+}
+
+func (cmd *info) Description() string {
+	return `Display info for the virtual networks NAME.
+
+Examples:
+  govc network.info vmnet8
+  govc network.info -json 'vmnet*'`
+}
+
+func (cmd *info) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() == 0 {
+		return flag.ErrHelp
+	}
+
+	nets, err := networks(ctx, cmd.ClientFlag, f.Args())
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&infoResult{Networks: nets})
+}
+
+type infoResult struct {
+	Networks []model.Network
+}
+
+func (r *infoResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	for _, net := range r.Networks {
+		fmt.Fprintf(tw, "Name:\t%s\n", net.Name)
+		fmt.Fprintf(tw, "  Type:\t%s\n", net.Type)
+		fmt.Fprintf(tw, "  DHCP:\t%s\n", net.Dhcp)
+
+		if net.Subnet != "" {
+			fmt.Fprintf(tw, "  Subnet:\t%s\n", net.Subnet)
+			fmt.Fprintf(tw, "  Mask:\t%s\n", net.Mask)
+		}
+	}
+
+	return tw.Flush()
+}
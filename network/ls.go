@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type ls struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+}
+
+func init() {
+	cli.Register("network.ls", &ls{})
+}
+
+func (cmd *ls) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+}
+
+func (cmd *ls) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *ls) Usage() string {
+	return "[NAME]..."
+}
+
+func (cmd *ls) Description() string {
+	return `List the virtual networks.
+
+NAME is a vmnet name and may be a glob pattern, all the networks are listed by default.
+
+Examples:
+  govc network.ls
+  govc network.ls 'vmnet[18]'
+  govc network.ls -json | jq -r '.Networks[] | select(.type == "nat") | .name'`
+}
+
+// networks returns the description of the networks matching the given names, all the networks by default
+func networks(ctx context.Context, c *flags.ClientFlag, names []string) ([]model.Network, error) {
+	client, err := c.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		names = []string{"*"}
+	}
+
+	// the descriptions come from a single listing rather than one per network
+	all, err := client.GetAllNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	info := make(map[string]model.Network, len(all.Vmnets))
+	for _, net := range all.Vmnets {
+		info[net.Name] = net
+	}
+
+	finder := find.NewFinder(client)
+
+	var res []model.Network
+
+	for _, name := range names {
+		nets, err := finder.NetworkList(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, net := range nets {
+			if i, ok := info[net.Reference().Value]; ok {
+				res = append(res, i)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (cmd *ls) Run(ctx context.Context, f *flag.FlagSet) error {
+	nets, err := networks(ctx, cmd.ClientFlag, f.Args())
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&lsResult{Networks: nets})
+}
+
+type lsResult struct {
+	Networks []model.Network
+}
+
+func (r *lsResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 3, 0, 2, ' ', 0)
+
+	for _, net := range r.Networks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", net.Name, net.Type, net.Subnet)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+func TestLs(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		tests := []struct {
+			args   []string
+			expect []string
+		}{
+			{nil, []string{"vmnet0", "vmnet1", "vmnet8"}},
+			{[]string{"vmnet[18]"}, []string{"vmnet1", "vmnet8"}},
+			{[]string{"vmnet8", "vmnet0"}, []string{"vmnet8", "vmnet0"}},
+		}
+
+		for _, test := range tests {
+			out, err := govc(t, c, append([]string{"network.ls", "-json"}, test.args...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var res lsResult
+			if err = json.Unmarshal([]byte(out), &res); err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, net := range res.Networks {
+				names = append(names, net.Name)
+			}
+
+			if fmt.Sprint(names) != fmt.Sprint(test.expect) {
+				t.Errorf("%v: expected %v, got %v", test.args, test.expect, names)
+			}
+		}
+
+		if _, err := govc(t, c, "network.ls", "enoent"); err == nil {
+			t.Error("expected an error listing an unknown network")
+		}
+
+		out, err := govc(t, c, "network.ls", "vmnet1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(out, "vmnet1") {
+			t.Errorf("unexpected output: %q", out)
+		}
+	})
+}
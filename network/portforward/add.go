@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type add struct {
+	*flags.VirtualMachineFlag
+
+	vmnet string
+	desc  string
+}
+
+func init() {
+	cli.Register("network.portforward.add", &add{})
+}
+
+func (cmd *add) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.vmnet, "vmnet", "vmnet8", "NAT virtual network")
+	f.StringVar(&cmd.desc, "desc", "", "Port forwarding description")
+}
+
+func (cmd *add) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *add) Usage() string {
+	return "PROTOCOL HOSTPORT [GUESTIP] GUESTPORT"
+}
+
+func (cmd *add) Description() string {
+	return `Forward the host port HOSTPORT to the port GUESTPORT of a guest on a NAT virtual network.
+
+PROTOCOL is tcp or udp. When a VM is specified the GUESTIP argument is omitted and
+the VM IP address is used, waiting for the VM to get one. A host port already
+forwarded to another guest is not replaced, remove it first.
+
+Examples:
+  govc network.portforward.add tcp 8080 192.168.38.128 80
+  govc network.portforward.add -vm $vm -desc ssh tcp 2222 22
+  govc network.portforward.add -vmnet vmnet2 udp 5353 192.168.100.10 53`
+}
+
+func (cmd *add) Run(ctx context.Context, f *flag.FlagSet) error {
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	args := f.Args()
+
+	if vm == nil && len(args) != 4 || vm != nil && len(args) != 3 {
+		return flag.ErrHelp
+	}
+
+	hostPort, err := port(args[1])
+	if err != nil {
+		return err
+	}
+
+	guestPort, err := port(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+
+	var guestIP string
+
+	if vm == nil {
+		guestIP = args[2]
+	} else {
+		err = cmd.WithCancel(ctx, func(ctx context.Context) error {
+			guestIP, err = vm.WaitForIPWithOptions(ctx, object.WaitForIPOptions{
+				Backoff: object.DefaultBackoff,
+				V4:      true,
+				DHCP:    true,
+			})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	net, err := network(ctx, cmd.ClientFlag, cmd.vmnet)
+	if err != nil {
+		return err
+	}
+
+	return net.AddPortForward(ctx, args[0], hostPort, guestIP, guestPort, cmd.desc)
+}
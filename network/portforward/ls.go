@@ -0,0 +1,124 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type ls struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+
+	vmnet string
+}
+
+func init() {
+	cli.Register("network.portforward.ls", &ls{})
+}
+
+func (cmd *ls) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.vmnet, "vmnet", "vmnet8", "NAT virtual network")
+}
+
+func (cmd *ls) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *ls) Description() string {
+	return `List the port forwardings of a NAT virtual network.
+
+Examples:
+  govc network.portforward.ls
+  govc network.portforward.ls -vmnet vmnet2 -json`
+}
+
+// network returns the virtual network named vmnet
+func network(ctx context.Context, c *flags.ClientFlag, vmnet string) (*object.Network, error) {
+	client, err := c.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	return find.NewFinder(client).Network(ctx, vmnet)
+}
+
+// port parses a port number argument
+func port(arg string) (int, error) {
+	port, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port %q", arg)
+	}
+
+	return port, nil
+}
+
+func (cmd *ls) Run(ctx context.Context, f *flag.FlagSet) error {
+	net, err := network(ctx, cmd.ClientFlag, cmd.vmnet)
+	if err != nil {
+		return err
+	}
+
+	forwards, err := net.PortForwards(ctx)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&lsResult{PortForwards: forwards})
+}
+
+type lsResult struct {
+	PortForwards []model.Portforward
+}
+
+func (r *lsResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 3, 0, 2, ' ', 0)
+
+	for _, forward := range r.PortForwards {
+		guest := "-"
+		if forward.Guest != nil {
+			guest = fmt.Sprintf("%s:%d", forward.Guest.Ip, forward.Guest.Port)
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", forward.Protocol, forward.Port, guest, forward.Desc)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,189 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+// forwards returns the port forwardings of vmnet8 as "protocol port guest:port"
+func forwards(t *testing.T, c *vim25.Client) []string {
+	t.Helper()
+
+	out, err := govc(t, c, "network.portforward.ls", "-json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res lsResult
+	if err = json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	var list []string
+	for _, forward := range res.PortForwards {
+		list = append(list, fmt.Sprintf("%s %d %s:%d", forward.Protocol, forward.Port, forward.Guest.Ip, forward.Guest.Port))
+	}
+
+	return list
+}
+
+func TestPortForward(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vmnet8, err := find.NewFinder(c).Network(ctx, "vmnet8")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := vmnet8.Info(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		guest := func(n byte) string {
+			ip := net.ParseIP(info.Subnet).To4()
+			ip[3] = n
+			return ip.String()
+		}
+
+		vmIP, err := c.GetIPAddress(vmID(ctx, t, c, "VM0"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, args := range [][]string{
+			{"tcp", "8080", guest(10), "80"},
+			{"tcp", "8080", guest(10), "80"}, // unchanged
+			{"udp", "8080", guest(11), "53"},
+			{"-vm", "VM0", "-desc", "ssh", "TCP", "2222", "22"},
+		} {
+			if _, err = govc(t, c, append([]string{"network.portforward.add"}, args...)...); err != nil {
+				t.Fatalf("%v: %s", args, err)
+			}
+		}
+
+		expect := []string{
+			"tcp 8080 " + guest(10) + ":80",
+			"udp 8080 " + guest(11) + ":53",
+			"tcp 2222 " + vmIP.Ip + ":22",
+		}
+
+		if list := forwards(t, c); fmt.Sprint(list) != fmt.Sprint(expect) {
+			t.Errorf("expected %v, got %v", expect, list)
+		}
+
+		for _, args := range [][]string{
+			{"tcp", "8080", guest(12), "80"},                     // already forwarded to another guest
+			{"tcp", "8081", "192.0.2.1", "80"},                   // not on the subnet
+			{"-vmnet", "vmnet1", "tcp", "8081", guest(10), "80"}, // not a NAT network
+			{"sctp", "8081", guest(10), "80"},
+			{"tcp", "0", guest(10), "80"},
+			{"tcp", "8081", guest(10), "65536"},
+			{"tcp", "http", guest(10), "80"},
+			{"tcp", "8081", "enoent", "80"},
+			{"tcp", "8081", "80"},
+		} {
+			if _, err = govc(t, c, append([]string{"network.portforward.add"}, args...)...); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		}
+
+		if list := forwards(t, c); fmt.Sprint(list) != fmt.Sprint(expect) {
+			t.Errorf("expected %v, got %v", expect, list)
+		}
+
+		out, err := govc(t, c, "network.portforward.ls")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(out, "ssh") || len(strings.Split(strings.TrimSpace(out), "\n")) != 3 {
+			t.Errorf("unexpected output: %s", out)
+		}
+
+		if _, err = govc(t, c, "network.portforward.remove", "tcp", "8080"); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, args := range [][]string{
+			{"tcp", "8080"},
+			{"-vmnet", "vmnet1", "udp", "8080"},
+			{"udp"},
+		} {
+			if _, err = govc(t, c, append([]string{"network.portforward.remove"}, args...)...); err == nil {
+				t.Errorf("%v: expected an error", args)
+			}
+		}
+
+		if list := forwards(t, c); fmt.Sprint(list) != fmt.Sprint(expect[1:]) {
+			t.Errorf("expected %v, got %v", expect[1:], list)
+		}
+	})
+}
+
+func vmID(ctx context.Context, t *testing.T, c *vim25.Client, name string) string {
+	t.Helper()
+
+	vm, err := find.NewFinder(c).VirtualMachine(ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return vm.Reference().Value
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type remove struct {
+	*flags.ClientFlag
+
+	vmnet string
+}
+
+func init() {
+	cli.Register("network.portforward.remove", &remove{})
+}
+
+func (cmd *remove) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.vmnet, "vmnet", "vmnet8", "NAT virtual network")
+}
+
+func (cmd *remove) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *remove) Usage() string {
+	return "PROTOCOL HOSTPORT"
+}
+
+func (cmd *remove) Description() string {
+	return `Remove the port forwarding of the host port HOSTPORT from a NAT virtual network.
+
+Examples:
+  govc network.portforward.remove tcp 8080
+  govc network.portforward.remove -vmnet vmnet2 udp 5353`
+}
+
+func (cmd *remove) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 2 {
+		return flag.ErrHelp
+	}
+
+	hostPort, err := port(f.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	net, err := network(ctx, cmd.ClientFlag, cmd.vmnet)
+	if err != nil {
+		return err
+	}
+
+	return net.RemovePortForward(ctx, f.Arg(0), hostPort)
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/Fred78290/govmrest/vim25"
 	"github.com/vmware/govmomi/vim25/types"
@@ -72,3 +73,16 @@ func (c Common) Name() string {
 func (c *Common) SetInventoryPath(p string) {
 	c.InventoryPath = p
 }
+
+// InventoryPath composes the inventory path of a virtual machine from the location of its .vmx file.
+// vmrest has no notion of folders, the path is the vmx location without its extension,
+// using forward slashes whatever the host OS is.
+func InventoryPath(vmx string) string {
+	p := strings.ReplaceAll(vmx, "\\", "/")
+
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+
+	return strings.TrimSuffix(path.Clean(p), path.Ext(p))
+}
@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// DHCPLeaseFiles returns the vmnet DHCP server lease files to look at for the given vmnet.
+// The lease files are only readable when vmrest runs on the local host.
+var DHCPLeaseFiles = func(vmnet string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			filepath.Join("/var/db/vmware", "vmnet-dhcpd-"+vmnet+".leases"),
+		}
+	case "windows":
+		return []string{
+			filepath.Join(os.Getenv("ProgramData"), "VMware", "vmnetdhcp.leases"),
+		}
+	default:
+		return []string{
+			filepath.Join("/etc/vmware", vmnet, "dhcpd", "dhcpd.leases"),
+		}
+	}
+}
+
+// sameMAC compares two MAC addresses whatever their notation is
+func sameMAC(a, b string) bool {
+	ha, err := net.ParseMAC(a)
+	if err != nil {
+		return strings.EqualFold(a, b)
+	}
+
+	hb, err := net.ParseMAC(b)
+	if err != nil {
+		return false
+	}
+
+	return ha.String() == hb.String()
+}
+
+// dhcpLease returns the IP address of the last lease granted to mac in the ISC dhcpd lease file.
+func dhcpLease(name, mac string) string {
+	f, err := os.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var ip, lease string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(scanner.Text()), ";"))
+
+		switch {
+		case len(fields) == 3 && fields[0] == "lease" && fields[2] == "{":
+			lease = fields[1]
+		case len(fields) == 3 && fields[0] == "hardware" && fields[1] == "ethernet":
+			if lease != "" && sameMAC(fields[2], mac) {
+				ip = lease
+			}
+		case len(fields) == 1 && fields[0] == "}":
+			lease = ""
+		}
+	}
+
+	return ip
+}
+
+// dhcpInfo returns the description of the network, IP reservations are only available when DHCP is enabled
+func (n Network) dhcpInfo(ctx context.Context) (*model.Network, error) {
+	info, err := n.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Dhcp != "true" {
+		return nil, fmt.Errorf("DHCP is not enabled on %s", info.Name)
+	}
+
+	return info, nil
+}
+
+// DHCPReservations returns the MAC to IP reservations of the network DHCP server.
+func (n Network) DHCPReservations(ctx context.Context) ([]model.MactoIp, error) {
+	if _, err := n.dhcpInfo(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := n.c.GetMACToIPs(n.r.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Mactoips, nil
+}
+
+// DHCPReservation returns the IP reserved for mac, an empty string if there is none.
+func (n Network) DHCPReservation(ctx context.Context, mac string) (string, error) {
+	reservations, err := n.DHCPReservations(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, reservation := range reservations {
+		if sameMAC(reservation.Mac, mac) {
+			return reservation.Ip, nil
+		}
+	}
+
+	return "", nil
+}
+
+// ReserveIP reserves the IP for mac in the network DHCP server, replacing the current reservation of mac.
+// The IP must be on the network subnet and not reserved for another MAC address.
+func (n Network) ReserveIP(ctx context.Context, mac, ip string) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return fmt.Errorf("invalid MAC address %q", mac)
+	}
+
+	addr := net.ParseIP(ip).To4()
+	if addr == nil {
+		return fmt.Errorf("invalid IPv4 address %q", ip)
+	}
+
+	info, err := n.dhcpInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !inSubnet(info, addr) {
+		return fmt.Errorf("%s is not on the %s subnet %s/%s", ip, info.Name, info.Subnet, info.Mask)
+	}
+
+	reservations, err := n.DHCPReservations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		if addr.Equal(net.ParseIP(reservation.Ip)) && !sameMAC(reservation.Mac, mac) {
+			return fmt.Errorf("%s is already reserved for %s", ip, reservation.Mac)
+		}
+	}
+
+	_, err = n.c.UpdateMacToIP(n.r.Value, hw.String(), &model.MacToIpParameter{IP: addr.String()})
+
+	return err
+}
+
+// RemoveReservation removes the IP reservation of mac from the network DHCP server.
+func (n Network) RemoveReservation(ctx context.Context, mac string) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return fmt.Errorf("invalid MAC address %q", mac)
+	}
+
+	ip, err := n.DHCPReservation(ctx, mac)
+	if err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return fmt.Errorf("no IP is reserved for %s on %s", mac, n.r.Value)
+	}
+
+	_, err = n.c.UpdateMacToIP(n.r.Value, hw.String(), &model.MacToIpParameter{})
+
+	return err
+}
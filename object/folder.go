@@ -19,7 +19,15 @@ Based on govc sources https://github.com/vmware/govmomi/govc
 package object
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/Fred78290/vmrest-go-client/client/model"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -32,3 +40,171 @@ func NewFolder(c *vim25.Client, ref types.ManagedObjectReference) *Folder {
 		Common: NewCommon(c, ref),
 	}
 }
+
+// RegisterVM adds the virtual machine defined by the vmx file to the Workstation library,
+// the task result is the new VM reference. The vmx file must be reachable from this host,
+// the name defaults to the vmx displayName.
+func (f Folder) RegisterVM(ctx context.Context, path string, name string) (*Task, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := vmx.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.Has("config.version") && !cfg.Has("virtualHW.version") {
+		return nil, fmt.Errorf("%s is not a virtual machine configuration file", path)
+	}
+
+	vmids, err := f.c.GetAllVMs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vmid := range vmids {
+		if samePath(vmid.Path, path) {
+			return nil, fmt.Errorf("%s is already registered as %s", path, vmid.Id)
+		}
+	}
+
+	if name == "" {
+		name = cfg.Get("displayName")
+	}
+
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	info, err := f.c.RegisterVM(&model.VmRegisterParameter{
+		Name: name,
+		Path: path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	vm := NewVirtualMachine(f.c, types.ManagedObjectReference{
+		Type:  "VirtualMachine",
+		Value: info.Id,
+	})
+
+	task := NewTask(f.c, f.r, "RegisterVM_Task", vm.vmRegisteredCondition(info.Id, true))
+	task.Result = vm.Reference()
+
+	return task, nil
+}
+
+// vmxTemplate is the configuration of a new virtual machine, before the config spec is applied
+var vmxTemplate = []vmx.Entry{
+	{Key: ".encoding", Value: "UTF-8"},
+	{Key: "config.version", Value: "8"},
+	{Key: "virtualHW.version", Value: "19"},
+	{Key: "pciBridge0.present", Value: "TRUE"},
+	{Key: "pciBridge4.present", Value: "TRUE"},
+	{Key: "pciBridge4.virtualDev", Value: "pcieRootPort"},
+	{Key: "pciBridge4.functions", Value: "8"},
+	{Key: "pciBridge5.present", Value: "TRUE"},
+	{Key: "pciBridge5.virtualDev", Value: "pcieRootPort"},
+	{Key: "pciBridge5.functions", Value: "8"},
+	{Key: "pciBridge6.present", Value: "TRUE"},
+	{Key: "pciBridge6.virtualDev", Value: "pcieRootPort"},
+	{Key: "pciBridge6.functions", Value: "8"},
+	{Key: "pciBridge7.present", Value: "TRUE"},
+	{Key: "pciBridge7.virtualDev", Value: "pcieRootPort"},
+	{Key: "pciBridge7.functions", Value: "8"},
+	{Key: "vmci0.present", Value: "TRUE"},
+	{Key: "hpet0.present", Value: "TRUE"},
+	{Key: "floppy0.present", Value: "FALSE"},
+	{Key: "guestOS", Value: "other-64"},
+	{Key: "memsize", Value: "1024"},
+	{Key: "numvcpus", Value: "1"},
+}
+
+func newVMX() *vmx.File {
+	cfg := vmx.New()
+
+	for _, e := range vmxTemplate {
+		cfg.Set(e.Key, e.Value)
+	}
+
+	return cfg
+}
+
+// DefaultDevices returns the devices of a virtual machine created by CreateVM before the devices of its config spec are added
+func DefaultDevices() VirtualDeviceList {
+	return readVMXDevices("", newVMX())
+}
+
+// CreateVM creates the virtual machine files in the directory of config.Files.VmPathName, the vmx path,
+// and adds the virtual machine to the Workstation library. The devices of config.DeviceChange are added
+// to the vmx, the disks with a create file operation are created. The files created are removed on failure.
+// The task result is the new VM reference.
+func (f Folder) CreateVM(ctx context.Context, config types.VirtualMachineConfigSpec) (*Task, error) {
+	if config.Files == nil || config.Files.VmPathName == "" {
+		return nil, fmt.Errorf("the vmx path of the virtual machine is required")
+	}
+
+	path, err := filepath.Abs(config.Files.VmPathName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = os.Stat(path); err == nil {
+		return nil, fmt.Errorf("the destination already exists: %s", path)
+	}
+
+	dir := filepath.Dir(path)
+	_, err = os.Stat(dir)
+	created := os.IsNotExist(err)
+
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var w *vmxDeviceConfig
+
+	task, err := func() (*Task, error) {
+		cfg := newVMX()
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		cfg.Set("displayName", name)
+		cfg.Set("nvram", name+".nvram")
+
+		settings := config
+		settings.DeviceChange = nil
+
+		if err := cfg.ApplyConfigSpec(settings); err != nil {
+			return nil, err
+		}
+
+		w = newVMXDeviceConfig(path, cfg)
+
+		if err := w.apply(config.DeviceChange); err != nil {
+			return nil, err
+		}
+
+		if err := w.save(); err != nil {
+			return nil, err
+		}
+
+		return f.RegisterVM(ctx, path, config.Name)
+	}()
+
+	if err != nil {
+		if w != nil {
+			w.rollback()
+		}
+
+		if created {
+			_ = os.RemoveAll(dir)
+		} else {
+			_ = os.Remove(path)
+		}
+
+		return nil, err
+	}
+
+	return task, nil
+}
@@ -0,0 +1,218 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// InventoryFile returns the path of the Workstation (or Fusion) virtual machine library,
+// the VMs listed by vmrest are the VMs registered in this file.
+// The file is only reachable when vmrest runs on the local host.
+var InventoryFile = func() string {
+	home, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "VMware Fusion", "vmInventory")
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), "VMware", "inventory.vmls")
+	default:
+		return filepath.Join(home, ".vmware", "inventory.vmls")
+	}
+}
+
+// DefaultVMDirectory returns the directory where Workstation (or Fusion) creates the new virtual machines
+var DefaultVMDirectory = func() string {
+	home, _ := os.UserHomeDir()
+
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Virtual Machines.localized")
+	case "windows":
+		return filepath.Join(home, "Documents", "Virtual Machines")
+	default:
+		return filepath.Join(home, "vmware")
+	}
+}
+
+var inventoryKey = regexp.MustCompile(`(?i)^(vmlist\d+|index\d+)\.(config|id)$`)
+
+// samePath compares two vmx paths, paths are case insensitive on windows and darwin
+func samePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+
+	if runtime.GOOS == "linux" {
+		return a == b
+	}
+
+	return strings.EqualFold(a, b)
+}
+
+// unregisterVMX removes the vmx from the inventory, it returns false if the vmx is not listed.
+func unregisterVMX(inventory, vmxPath string) (bool, error) {
+	vmls, err := vmx.Load(inventory)
+	if err != nil {
+		return false, err
+	}
+
+	var prefixes []string
+
+	for _, e := range vmls.Entries() {
+		if m := inventoryKey.FindStringSubmatch(e.Key); m != nil && samePath(e.Value, vmxPath) {
+			prefixes = append(prefixes, m[1]+".")
+		}
+	}
+
+	if len(prefixes) == 0 {
+		return false, nil
+	}
+
+	for _, prefix := range prefixes {
+		vmls.RemovePrefix(prefix)
+	}
+
+	if count, err := strconv.Atoi(vmls.Get("index.count")); err == nil {
+		// the search index entries are numbered from 0 to index.count - 1
+		n := 0
+
+		for i := 0; i < count; i++ {
+			prefix := fmt.Sprintf("index%d.", i)
+			found := false
+
+			for _, e := range vmls.Entries() {
+				if strings.HasPrefix(strings.ToLower(e.Key), prefix) {
+					vmls.Rename(e.Key, fmt.Sprintf("index%d.%s", n, e.Key[len(prefix):]))
+					found = true
+				}
+			}
+
+			if found {
+				n++
+			}
+		}
+
+		vmls.Set("index.count", strconv.Itoa(n))
+	}
+
+	return true, vmls.Save(inventory)
+}
+
+// localInventory returns the library file when it is the one read by vmrest:
+// vmrest must listen on a loopback address and the file must list every VM reported by vmrest.
+func (v VirtualMachine) localInventory() (string, error) {
+	inventory := InventoryFile()
+
+	rc, ok := v.c.Client.(*vim25.RESTClient)
+	if !ok {
+		return "", fmt.Errorf("cannot check that vmrest runs on this host, %s is not edited", inventory)
+	}
+
+	host := rc.URL().Hostname()
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return "", fmt.Errorf("vmrest runs on %s, %s of this host is not edited", host, inventory)
+	}
+
+	vmls, err := vmx.Load(inventory)
+	if err != nil {
+		return "", err
+	}
+
+	vmids, err := v.c.GetAllVMs()
+	if err != nil {
+		return "", err
+	}
+
+	for _, vmid := range vmids {
+		listed := false
+
+		for _, e := range vmls.Entries() {
+			if inventoryKey.MatchString(e.Key) && samePath(e.Value, vmid.Path) {
+				listed = true
+				break
+			}
+		}
+
+		if !listed {
+			return "", fmt.Errorf("%s does not list %s, it is not the library of vmrest and is not edited", inventory, vmid.Path)
+		}
+	}
+
+	return inventory, nil
+}
+
+// UnregisterTimeout bounds the wait for vmrest to stop listing an unregistered virtual machine
+var UnregisterTimeout = time.Minute
+
+// Unregister removes the virtual machine from the Workstation library, its files are kept.
+// vmrest has no API for this, the library file is edited locally. It is refused unless vmrest
+// listens on a loopback address and the library lists every VM reported by vmrest.
+func (v VirtualMachine) Unregister(ctx context.Context) error {
+	state, err := v.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if state != types.VirtualMachinePowerStatePoweredOff {
+		return &InvalidPowerStateError{
+			RequestedState: types.VirtualMachinePowerStatePoweredOff,
+			ExistingState:  state,
+		}
+	}
+
+	vmxPath, err := v.VmxPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	inventory, err := v.localInventory()
+	if err != nil {
+		return err
+	}
+
+	found, err := unregisterVMX(inventory, vmxPath)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("%s is not registered in %s", vmxPath, inventory)
+	}
+
+	wctx, cancel := context.WithTimeout(ctx, UnregisterTimeout)
+	defer cancel()
+
+	err = DefaultBackoff.Poll(wctx, v.vmRegisteredCondition(v.r.Value, false))
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return fmt.Errorf("%s is removed from %s but vmrest still lists it after %s", vmxPath, inventory, UnregisterTimeout)
+	}
+
+	return err
+}
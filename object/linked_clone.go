@@ -0,0 +1,440 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	ErrLinkedClones = errors.New("the virtual machine has linked clones")
+)
+
+var (
+	diskKey             = regexp.MustCompile(`(?i)^((ide|scsi|sata|nvme)\d+:\d+)\.filename$`)
+	generatedAddressKey = regexp.MustCompile(`(?i)^ethernet\d+\.generatedAddress(Offset)?$`)
+)
+
+type vmxDisk struct {
+	node     string
+	fileName string
+}
+
+// vmxDisks returns the virtual disks attached to the vmx
+func vmxDisks(cfg *vmx.File) []vmxDisk {
+	var disks []vmxDisk
+
+	for _, e := range cfg.Entries() {
+		m := diskKey.FindStringSubmatch(e.Key)
+		if m == nil || !strings.HasSuffix(strings.ToLower(e.Value), ".vmdk") {
+			continue
+		}
+
+		node := m[1]
+
+		if strings.EqualFold(cfg.Get(node+".present"), "FALSE") || strings.Contains(strings.ToLower(cfg.Get(node+".deviceType")), "cdrom") {
+			continue
+		}
+
+		disks = append(disks, vmxDisk{node: node, fileName: e.Value})
+	}
+
+	return disks
+}
+
+// vmsdPath returns the snapshot database path of the vmx
+func vmsdPath(vmxPath string) string {
+	return strings.TrimSuffix(vmxPath, filepath.Ext(vmxPath)) + ".vmsd"
+}
+
+// resolve returns the path of a file referenced by the vmx
+func resolve(vmxPath, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+
+	return filepath.Join(filepath.Dir(vmxPath), name)
+}
+
+// VmxPath returns the path of the virtual machine .vmx file
+func (v VirtualMachine) VmxPath(ctx context.Context) (string, error) {
+	vmids, err := v.c.GetAllVMs()
+	if err != nil {
+		return "", err
+	}
+
+	for _, vmid := range vmids {
+		if vmid.Id == v.r.Value {
+			return vmid.Path, nil
+		}
+	}
+
+	return "", fmt.Errorf("vm %s not found", v.r.Value)
+}
+
+// localVMX returns the path of the .vmx file, which must be reachable from this host
+func (v VirtualMachine) localVMX(ctx context.Context) (string, error) {
+	vmxPath, err := v.VmxPath(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = os.Stat(vmxPath); err != nil {
+		return "", fmt.Errorf("the virtual machine files must be reachable from this host: %w", err)
+	}
+
+	return vmxPath, nil
+}
+
+// readVMSD reads the snapshot database of the vmx, a missing database is read as an empty file
+func readVMSD(vmxPath string) (*vmx.File, error) {
+	vmsd, err := vmx.Load(vmsdPath(vmxPath))
+	if os.IsNotExist(err) {
+		return vmx.New(), nil
+	}
+
+	return vmsd, err
+}
+
+// currentSnapshot returns the index of the current snapshot in the vmsd, or -1
+func currentSnapshot(vmsd *vmx.File) int {
+	current := vmsd.Get("snapshot.current")
+
+	for i := 0; vmsd.Has(fmt.Sprintf("snapshot%d.uid", i)); i++ {
+		if current != "" && vmsd.Get(fmt.Sprintf("snapshot%d.uid", i)) == current {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// createSnapshot freezes the disks of a powered off virtual machine, redirecting its writes to new child disks,
+// and records the frozen disks as the current snapshot.
+func createSnapshot(vmxPath string, cfg *vmx.File, vmsd *vmx.File) (int, error) {
+	disks := vmxDisks(cfg)
+	if len(disks) == 0 {
+		return -1, fmt.Errorf("%s: no virtual disk to link to", vmxPath)
+	}
+
+	uid, _ := strconv.Atoi(vmsd.Get("snapshot.lastUID"))
+	uid++
+
+	index := 0
+	for vmsd.Has(fmt.Sprintf("snapshot%d.uid", index)) {
+		index++
+	}
+
+	prefix := fmt.Sprintf("snapshot%d.", index)
+	now := time.Now().UnixMicro()
+
+	if parent := vmsd.Get("snapshot.current"); parent != "" {
+		vmsd.Set(prefix+"parent", parent)
+	}
+
+	vmsd.Set(".encoding", "UTF-8")
+	vmsd.Set(prefix+"uid", strconv.Itoa(uid))
+	vmsd.Set(prefix+"displayName", "Linked clone base")
+	vmsd.Set(prefix+"createTimeHigh", strconv.FormatInt(now>>32, 10))
+	vmsd.Set(prefix+"createTimeLow", strconv.FormatInt(int64(int32(now)), 10))
+	vmsd.Set(prefix+"numDisks", strconv.Itoa(len(disks)))
+
+	var deltas []string
+
+	// rollback points the vmx back to the frozen disks and deletes the child disks created
+	rollback := func(err error, saved bool) (int, error) {
+		for _, disk := range disks {
+			cfg.Set(disk.node+".fileName", disk.fileName)
+		}
+
+		if saved {
+			if serr := cfg.Save(vmxPath); serr != nil {
+				return -1, fmt.Errorf("%s, restoring %s: %s", err, vmxPath, serr)
+			}
+		}
+
+		for _, delta := range deltas {
+			_ = vmdk.Delete(delta)
+		}
+
+		return -1, err
+	}
+
+	for i, disk := range disks {
+		base := strings.TrimSuffix(disk.fileName, filepath.Ext(disk.fileName))
+		delta := ""
+
+		for n := 1; ; n++ {
+			delta = fmt.Sprintf("%s-%06d.vmdk", base, n)
+			if _, err := os.Stat(resolve(vmxPath, delta)); os.IsNotExist(err) {
+				break
+			}
+		}
+
+		if err := vmdk.CreateChild(resolve(vmxPath, delta), disk.fileName); err != nil {
+			return rollback(err, false)
+		}
+
+		deltas = append(deltas, resolve(vmxPath, delta))
+
+		cfg.Set(disk.node+".fileName", delta)
+		vmsd.Set(fmt.Sprintf("%sdisk%d.fileName", prefix, i), disk.fileName)
+		vmsd.Set(fmt.Sprintf("%sdisk%d.node", prefix, i), disk.node)
+	}
+
+	vmsd.Set("snapshot.lastUID", strconv.Itoa(uid))
+	vmsd.Set("snapshot.current", strconv.Itoa(uid))
+	vmsd.Set("snapshot.numSnapshots", strconv.Itoa(index+1))
+
+	if err := cfg.Save(vmxPath); err != nil {
+		return rollback(err, false)
+	}
+
+	if err := vmsd.Save(vmsdPath(vmxPath)); err != nil {
+		return rollback(err, true)
+	}
+
+	return index, nil
+}
+
+// linkedClones returns the .vmx path of the linked clones of the virtual machine that still exist
+func linkedClones(vmxPath string) ([]string, error) {
+	vmsd, err := readVMSD(vmxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var clones []string
+
+	for i := 0; vmsd.Has(fmt.Sprintf("snapshot%d.uid", i)); i++ {
+		n, _ := strconv.Atoi(vmsd.Get(fmt.Sprintf("snapshot%d.numClones", i)))
+
+		for j := 0; j < n; j++ {
+			clone := vmsd.Get(fmt.Sprintf("snapshot%d.clone%d", i, j))
+
+			if _, err := os.Stat(clone); err == nil {
+				clones = append(clones, clone)
+			}
+		}
+	}
+
+	return clones, nil
+}
+
+// LinkedClones returns the .vmx path of the linked clones of the virtual machine that still exist,
+// they are only known when the virtual machine files are reachable from this host.
+func (v VirtualMachine) LinkedClones(ctx context.Context) ([]string, error) {
+	vmxPath, err := v.localVMX(ctx)
+	if err != nil {
+		return nil, nil
+	}
+
+	return linkedClones(vmxPath)
+}
+
+// forgetLinkedClone removes a destroyed linked clone from the snapshot database of its parent
+func forgetLinkedClone(parentPath, clonePath string) error {
+	vmsd, err := readVMSD(parentPath)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; vmsd.Has(fmt.Sprintf("snapshot%d.uid", i)); i++ {
+		prefix := fmt.Sprintf("snapshot%d.", i)
+		n, _ := strconv.Atoi(vmsd.Get(prefix + "numClones"))
+
+		var clones []string
+
+		for j := 0; j < n; j++ {
+			if clone := vmsd.Get(fmt.Sprintf("%sclone%d", prefix, j)); clone != clonePath {
+				clones = append(clones, clone)
+			}
+		}
+
+		if len(clones) == n {
+			continue
+		}
+
+		vmsd.RemovePrefix(prefix + "clone")
+		vmsd.Remove(prefix + "numClones")
+
+		if len(clones) != 0 {
+			vmsd.Set(prefix+"numClones", strconv.Itoa(len(clones)))
+		}
+
+		for j, clone := range clones {
+			vmsd.Set(fmt.Sprintf("%sclone%d", prefix, j), clone)
+		}
+
+		return vmsd.Save(vmsdPath(parentPath))
+	}
+
+	return nil
+}
+
+// linkedClone creates a virtual machine whose disks are children of the current snapshot disks of v,
+// a snapshot is taken first if v has none. The vmx and vmsd files are edited locally, the clone is then
+// registered with vmrest. It returns the new virtual machine id.
+func (v VirtualMachine) linkedClone(ctx context.Context, name string) (string, error) {
+	parentPath, err := v.localVMX(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	parent, err := vmx.Load(parentPath)
+	if err != nil {
+		return "", err
+	}
+
+	vmsd, err := readVMSD(parentPath)
+	if err != nil {
+		return "", err
+	}
+
+	snapshot := currentSnapshot(vmsd)
+
+	if snapshot < 0 {
+		state, err := v.PowerState(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		if state != types.VirtualMachinePowerStatePoweredOff {
+			return "", &InvalidPowerStateError{
+				RequestedState: types.VirtualMachinePowerStatePoweredOff,
+				ExistingState:  state,
+			}
+		}
+
+		if snapshot, err = createSnapshot(parentPath, parent, vmsd); err != nil {
+			return "", err
+		}
+	}
+
+	dir := filepath.Join(filepath.Dir(filepath.Dir(parentPath)), name)
+	vmxPath := filepath.Join(dir, name+".vmx")
+
+	if _, err = os.Stat(dir); err == nil {
+		return "", fmt.Errorf("the destination already exists: %s", dir)
+	}
+
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	id, err := func() (string, error) {
+		prefix := fmt.Sprintf("snapshot%d.", snapshot)
+		numDisks, _ := strconv.Atoi(vmsd.Get(prefix + "numDisks"))
+		cfg := parent.Copy()
+
+		// disks that are not part of the snapshot are not shared with the clone
+		for _, disk := range vmxDisks(cfg) {
+			cfg.RemovePrefix(disk.node + ".")
+		}
+
+		for i := 0; i < numDisks; i++ {
+			node := vmsd.Get(fmt.Sprintf("%sdisk%d.node", prefix, i))
+			base := resolve(parentPath, vmsd.Get(fmt.Sprintf("%sdisk%d.fileName", prefix, i)))
+			// the snapshot disks may share a base name, the node makes the name unique: "disk-scsi0-0-cl1.vmdk"
+			delta := fmt.Sprintf("%s-%s-cl1.vmdk", strings.TrimSuffix(filepath.Base(base), filepath.Ext(base)), strings.ReplaceAll(node, ":", "-"))
+
+			if err := vmdk.CreateChild(filepath.Join(dir, delta), base); err != nil {
+				return "", err
+			}
+
+			for _, e := range parent.Entries() {
+				if strings.HasPrefix(strings.ToLower(e.Key), strings.ToLower(node)+".") {
+					cfg.Set(e.Key, e.Value)
+				}
+			}
+
+			cfg.Set(node+".present", "TRUE")
+			cfg.Set(node+".fileName", delta)
+		}
+
+		for _, key := range []string{"uuid.bios", "uuid.location", "vc.uuid", "checkpoint.vmState", "sched.swap.derivedName"} {
+			cfg.RemovePrefix(key)
+		}
+
+		// let Workstation generate new MAC addresses
+		cfg.RemoveFunc(func(e vmx.Entry) bool {
+			return generatedAddressKey.MatchString(e.Key)
+		})
+
+		cfg.Set("displayName", name)
+		if cfg.Has("nvram") {
+			cfg.Set("nvram", name+".nvram")
+		}
+		if cfg.Has("extendedConfigFile") {
+			cfg.Set("extendedConfigFile", name+".vmxf")
+		}
+
+		// the clone chain, cloneOf0 is the direct parent
+		numCloneOf, _ := strconv.Atoi(parent.Get("numCloneOf"))
+		cfg.RemovePrefix("cloneOf")
+		cfg.Remove("numCloneOf")
+		cfg.Set("cloneOf0", parentPath)
+		for i := 0; i < numCloneOf; i++ {
+			cfg.Set(fmt.Sprintf("cloneOf%d", i+1), parent.Get(fmt.Sprintf("cloneOf%d", i)))
+		}
+		cfg.Set("numCloneOf", strconv.Itoa(numCloneOf+1))
+
+		if err := cfg.Save(vmxPath); err != nil {
+			return "", err
+		}
+
+		// the parent lists the clone before it is registered, the parent cannot be destroyed while a registered clone exists
+		numClones, _ := strconv.Atoi(vmsd.Get(prefix + "numClones"))
+		vmsd.Set(fmt.Sprintf("%sclone%d", prefix, numClones), vmxPath)
+		vmsd.Set(prefix+"numClones", strconv.Itoa(numClones+1))
+
+		if err := vmsd.Save(vmsdPath(parentPath)); err != nil {
+			return "", err
+		}
+
+		info, err := v.c.RegisterVM(&model.VmRegisterParameter{
+			Name: name,
+			Path: vmxPath,
+		})
+		if err != nil {
+			_ = forgetLinkedClone(parentPath, vmxPath)
+			return "", err
+		}
+
+		return info.Id, nil
+	}()
+
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+
+	return id, nil
+}
@@ -19,10 +19,19 @@ Based on govc sources https://github.com/vmware/govmomi/govc
 package object
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
 	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/vmrest-go-client/client/model"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
+// Network is a vmrest virtual network, the reference value is the vmnet name.
 type Network struct {
 	Common
 }
@@ -32,3 +41,225 @@ func NewNetwork(c *vim25.Client, ref types.ManagedObjectReference) *Network {
 		Common: NewCommon(c, ref),
 	}
 }
+
+// NetworkInventoryPath returns the inventory path of the vmnet name, networks live at the inventory root.
+func NetworkInventoryPath(name string) string {
+	return path.Join("/", name)
+}
+
+// Info returns the vmrest description of the network.
+func (n Network) Info(ctx context.Context) (*model.Network, error) {
+	networks, err := n.c.GetAllNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, network := range networks.Vmnets {
+		if network.Name == n.r.Value {
+			return &network, nil
+		}
+	}
+
+	return nil, fmt.Errorf("network %q not found", n.r.Value)
+}
+
+// inSubnet reports whether ip is on the subnet of the network, any address is on a network without subnet.
+func inSubnet(info *model.Network, ip net.IP) bool {
+	subnet := net.ParseIP(info.Subnet).To4()
+	mask := net.IPMask(net.ParseIP(info.Mask).To4())
+
+	if subnet == nil || mask == nil {
+		return true
+	}
+
+	return subnet.Mask(mask).Equal(ip.Mask(mask))
+}
+
+// NetworkTypes are the types of the virtual networks that can be created or changed
+var NetworkTypes = []string{"hostonly", "nat"}
+
+// NetworkSpec describes the settings of a host-only or NAT virtual network, empty fields are left unchanged.
+// The Subnet is an IPv4 network address, or a CIDR when the Mask is empty.
+type NetworkSpec struct {
+	Type   string
+	Subnet string
+	Mask   string
+	DHCP   *bool
+}
+
+// parameter returns the vmrest settings of the spec applied to the current network settings
+func (spec NetworkSpec) parameter(info model.Network) (*model.Network, error) {
+	switch strings.ToLower(spec.Type) {
+	case "":
+	case "hostonly":
+		info.Type = "hostOnly"
+	case "nat":
+		info.Type = "nat"
+	default:
+		return nil, fmt.Errorf("invalid network type %q, must be one of %s", spec.Type, strings.Join(NetworkTypes, "|"))
+	}
+
+	if spec.DHCP != nil {
+		info.Dhcp = strconv.FormatBool(*spec.DHCP)
+	}
+
+	if spec.Subnet != "" {
+		subnet, mask := spec.Subnet, spec.Mask
+
+		if mask == "" {
+			_, cidr, err := net.ParseCIDR(subnet)
+			if err != nil {
+				return nil, fmt.Errorf("invalid subnet %q, must be a network address with a mask or a CIDR", subnet)
+			}
+
+			subnet, mask = cidr.IP.String(), net.IP(cidr.Mask).String()
+		}
+
+		info.Subnet, info.Mask = subnet, mask
+	} else if spec.Mask != "" {
+		info.Mask = spec.Mask
+	}
+
+	if info.Subnet != "" || info.Mask != "" {
+		ipnet, err := subnetOf(info)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ipnet.IP.Equal(net.ParseIP(info.Subnet)) {
+			return nil, fmt.Errorf("invalid subnet %s/%s, the network address is %s", info.Subnet, info.Mask, ipnet.IP)
+		}
+	}
+
+	return &info, nil
+}
+
+// subnetOf returns the IPv4 subnet of the network
+func subnetOf(info model.Network) (*net.IPNet, error) {
+	ip := net.ParseIP(info.Subnet).To4()
+	mask := net.IPMask(net.ParseIP(info.Mask).To4())
+
+	if ip == nil || mask == nil {
+		return nil, fmt.Errorf("invalid subnet %s/%s", info.Subnet, info.Mask)
+	}
+
+	if ones, bits := mask.Size(); bits == 0 || ones == 0 || ones > 30 {
+		return nil, fmt.Errorf("invalid mask %s", info.Mask)
+	}
+
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}, nil
+}
+
+// overlaps returns an error when the subnet of the network overlaps the subnet of another virtual network
+func overlaps(info *model.Network, networks []model.Network) error {
+	if info.Subnet == "" {
+		return nil
+	}
+
+	subnet, err := subnetOf(*info)
+	if err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		if network.Name == info.Name || network.Subnet == "" {
+			continue
+		}
+
+		other, err := subnetOf(network)
+		if err != nil {
+			continue
+		}
+
+		if subnet.Contains(other.IP) || other.Contains(subnet.IP) {
+			return fmt.Errorf("subnet %s of %s overlaps subnet %s of %s", subnet, info.Name, other, network.Name)
+		}
+	}
+
+	return nil
+}
+
+// CreateNetwork creates the virtual network name, a host-only network unless the spec type is nat.
+// The subnet defaults to the one chosen by vmrest. The network is kept, and named in the error,
+// if its settings cannot be applied as vmrest has no API to delete it.
+func CreateNetwork(ctx context.Context, c *vim25.Client, name string, spec NetworkSpec) (*Network, error) {
+	networks, err := c.GetAllNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.Type == "" {
+		spec.Type = "hostonly"
+	}
+
+	param, err := spec.parameter(model.Network{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = overlaps(param, networks.Vmnets); err != nil {
+		return nil, err
+	}
+
+	info, err := c.CreateNetwork(&model.CreateVmnetParameter{
+		Name: name,
+		Type: param.Type,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	n := NewNetwork(c, types.ManagedObjectReference{
+		Type:  "Network",
+		Value: info.Name,
+	})
+
+	n.InventoryPath = NetworkInventoryPath(info.Name)
+
+	if spec.Subnet != "" || spec.Mask != "" || spec.DHCP != nil {
+		// vmrest cannot delete a virtual network, the error names the network left with the default settings
+		if err = n.Change(ctx, spec); err != nil {
+			return nil, fmt.Errorf("%s is created with the default settings, configuring it failed: %w", info.Name, err)
+		}
+	}
+
+	return n, nil
+}
+
+// Change applies the spec to the virtual network, the subnet must not overlap the subnet of another virtual network.
+func (n Network) Change(ctx context.Context, spec NetworkSpec) error {
+	networks, err := n.c.GetAllNetworks()
+	if err != nil {
+		return err
+	}
+
+	var info *model.Network
+
+	for _, network := range networks.Vmnets {
+		if network.Name == n.r.Value {
+			info = &network
+			break
+		}
+	}
+
+	if info == nil {
+		return fmt.Errorf("network %q not found", n.r.Value)
+	}
+
+	if info.Type == "bridged" {
+		return fmt.Errorf("%s is a bridged network and cannot be changed", info.Name)
+	}
+
+	param, err := spec.parameter(*info)
+	if err != nil {
+		return err
+	}
+
+	if err = overlaps(param, networks.Vmnets); err != nil {
+		return err
+	}
+
+	_, err = n.c.UpdateNetwork(n.r.Value, param)
+
+	return err
+}
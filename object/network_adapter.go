@@ -0,0 +1,277 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// NetworkAdapterTypes are the network types a network adapter can be connected to,
+// a custom adapter is connected to the vmnet named in its spec.
+var NetworkAdapterTypes = []string{"bridged", "nat", "hostonly", "custom"}
+
+// NetworkAdapterDevices are the virtual device types of a network adapter
+var NetworkAdapterDevices = []string{"e1000", "e1000e", "vmxnet3"}
+
+// NetworkAdapterSpec describes a network adapter. The Type and Vmnet are applied through vmrest,
+// the VirtualDev and the static MacAddress are written to the vmx file, a "-" MacAddress
+// reverts the adapter to a generated address.
+type NetworkAdapterSpec struct {
+	Type       string
+	Vmnet      string
+	VirtualDev string
+	MacAddress string
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (spec NetworkAdapterSpec) validate() error {
+	if spec.Type != "" && !contains(NetworkAdapterTypes, spec.Type) {
+		return fmt.Errorf("invalid network type %q, must be one of %s", spec.Type, strings.Join(NetworkAdapterTypes, "|"))
+	}
+
+	if spec.Type == "custom" && spec.Vmnet == "" {
+		return fmt.Errorf("a custom network adapter requires a vmnet")
+	}
+
+	if spec.VirtualDev != "" && !contains(NetworkAdapterDevices, spec.VirtualDev) {
+		return fmt.Errorf("invalid network adapter device %q, must be one of %s", spec.VirtualDev, strings.Join(NetworkAdapterDevices, "|"))
+	}
+
+	if spec.MacAddress != "" && spec.MacAddress != "-" {
+		return validStaticMAC(spec.MacAddress)
+	}
+
+	return nil
+}
+
+// validStaticMAC returns an error unless the address is in the VMware reserved range Workstation accepts for static addresses
+func validStaticMAC(address string) error {
+	mac, err := net.ParseMAC(address)
+	if err != nil || len(mac) != 6 || mac[0] != 0x00 || mac[1] != 0x50 || mac[2] != 0x56 || mac[3] > 0x3f {
+		return fmt.Errorf("invalid static MAC address %q, must be in the range 00:50:56:00:00:00 to 00:50:56:3F:FF:FF", address)
+	}
+
+	return nil
+}
+
+// vmxPath returns the path of the vmx file when the spec has settings vmrest cannot apply
+func (spec NetworkAdapterSpec) vmxPath(ctx context.Context, v VirtualMachine) (string, error) {
+	if spec.VirtualDev == "" && spec.MacAddress == "" {
+		return "", nil
+	}
+
+	vmxPath, ok := v.editableVMX(ctx)
+	if !ok {
+		return "", fmt.Errorf("%w: the network adapter device and MAC address require the virtual machine to be powered off and its files reachable from this host", ErrNotSupported)
+	}
+
+	return vmxPath, nil
+}
+
+// configure writes the device type and the static MAC address of the adapter to the vmx file
+func (spec NetworkAdapterSpec) configure(vmxPath string, nic *model.NicDevice) error {
+	cfg, err := vmx.Load(vmxPath)
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("ethernet%d.", nic.Index-1)
+
+	if spec.VirtualDev != "" {
+		cfg.Set(prefix+"virtualDev", spec.VirtualDev)
+	}
+
+	switch spec.MacAddress {
+	case "":
+	case "-":
+		cfg.Set(prefix+"addressType", "generated")
+		cfg.Remove(prefix + "address")
+		nic.MacAddress = cfg.Get(prefix + "generatedAddress")
+	default:
+		cfg.Set(prefix+"addressType", "static")
+		cfg.Set(prefix+"address", strings.ToUpper(spec.MacAddress))
+		nic.MacAddress = cfg.Get(prefix + "address")
+	}
+
+	return cfg.Save(vmxPath)
+}
+
+// NetworkAdapters returns the network adapters of the VirtualMachine, the vmrest index of the ethernetN adapter is N + 1
+func (v VirtualMachine) NetworkAdapters(ctx context.Context) ([]model.NicDevice, error) {
+	res, err := v.c.GetAllNICDevices(v.r.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Nics, nil
+}
+
+// NetworkAdapter returns the network adapter with the given vmrest index
+func (v VirtualMachine) NetworkAdapter(ctx context.Context, index int) (*model.NicDevice, error) {
+	nics, err := v.NetworkAdapters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range nics {
+		if nics[i].Index == index {
+			return &nics[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("network adapter %d not found", index)
+}
+
+// AddNetworkAdapter adds a network adapter to the VirtualMachine, which must be powered off
+func (v VirtualMachine) AddNetworkAdapter(ctx context.Context, spec NetworkAdapterSpec) (*model.NicDevice, error) {
+	if spec.Type == "" {
+		spec.Type = "nat"
+	}
+
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	state, err := v.PowerState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if state != types.VirtualMachinePowerStatePoweredOff {
+		return nil, &InvalidPowerStateError{
+			RequestedState: types.VirtualMachinePowerStatePoweredOff,
+			ExistingState:  state,
+		}
+	}
+
+	vmxPath, err := spec.vmxPath(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+
+	nic, err := v.c.CreateNICDevice(v.r.Value, &model.NicDeviceParameter{Type: spec.Type, Vmnet: spec.Vmnet})
+	if err != nil {
+		return nil, err
+	}
+
+	if vmxPath != "" {
+		if err = spec.configure(vmxPath, nic); err != nil {
+			// the adapter is only added with all its settings
+			if derr := v.c.DeleteNICDevice(v.r.Value, nic.Index); derr != nil {
+				return nil, fmt.Errorf("%s, removing network adapter %d: %s", err, nic.Index, derr)
+			}
+
+			return nil, err
+		}
+	}
+
+	return nic, nil
+}
+
+// ChangeNetworkAdapter changes the network adapter with the given vmrest index, the empty fields of the spec are left unchanged
+func (v VirtualMachine) ChangeNetworkAdapter(ctx context.Context, index int, spec NetworkAdapterSpec) (*model.NicDevice, error) {
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	vmxPath, err := spec.vmxPath(ctx, v)
+	if err != nil {
+		return nil, err
+	}
+
+	var nic *model.NicDevice
+
+	if spec.Type != "" {
+		nic, err = v.c.UpdateNICDevice(v.r.Value, index, &model.NicDeviceParameter{Type: spec.Type, Vmnet: spec.Vmnet})
+	} else {
+		nic, err = v.NetworkAdapter(ctx, index)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if vmxPath != "" {
+		if err = spec.configure(vmxPath, nic); err != nil {
+			return nil, err
+		}
+	}
+
+	return nic, nil
+}
+
+// RemoveNetworkAdapter removes the network adapter with the given vmrest index, the VirtualMachine must be powered off
+func (v VirtualMachine) RemoveNetworkAdapter(ctx context.Context, index int) error {
+	return v.c.DeleteNICDevice(v.r.Value, index)
+}
+
+// EthernetCard returns a new network adapter device of the spec, a nat e1000 adapter by default
+func (spec NetworkAdapterSpec) EthernetCard(l VirtualDeviceList) (types.BaseVirtualDevice, error) {
+	if spec.Type == "" {
+		spec.Type = "nat"
+	}
+
+	if spec.VirtualDev == "" {
+		spec.VirtualDev = "e1000"
+	}
+
+	if err := spec.validate(); err != nil {
+		return nil, err
+	}
+
+	vmnet := spec.Vmnet
+
+	switch spec.Type {
+	case "bridged":
+		vmnet = "vmnet0"
+	case "hostonly":
+		vmnet = "vmnet1"
+	case "nat":
+		vmnet = "vmnet8"
+	}
+
+	device, err := l.CreateEthernetCard(spec.VirtualDev, &types.VirtualEthernetCardNetworkBackingInfo{
+		VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{DeviceName: vmnet},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.MacAddress != "" && spec.MacAddress != "-" {
+		card := device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+		card.AddressType = string(types.VirtualEthernetCardMacTypeManual)
+		card.MacAddress = spec.MacAddress
+	}
+
+	return device, nil
+}
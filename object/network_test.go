@@ -0,0 +1,138 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func network(c *vim25.Client, name string) *object.Network {
+	return object.NewNetwork(c, types.ManagedObjectReference{Type: "Network", Value: name})
+}
+
+func TestNetworkChange(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vmnet1 := network(c, "vmnet1")
+
+		nat, err := network(c, "vmnet8").Info(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			spec   object.NetworkSpec
+			subnet string
+			mask   string
+		}{
+			{object.NetworkSpec{Subnet: "10.10.0.0/16"}, "10.10.0.0", "255.255.0.0"},
+			{object.NetworkSpec{Subnet: "10.20.0.0", Mask: "255.255.255.0"}, "10.20.0.0", "255.255.255.0"},
+			{object.NetworkSpec{Mask: "255.255.0.0"}, "10.20.0.0", "255.255.0.0"},
+			{object.NetworkSpec{Subnet: "10.30.0.1/24"}, "10.30.0.0", "255.255.255.0"},
+			{object.NetworkSpec{Subnet: "10.40.0.0/30"}, "10.40.0.0", "255.255.255.252"},
+		}
+
+		for _, test := range tests {
+			if err = vmnet1.Change(ctx, test.spec); err != nil {
+				t.Fatalf("%+v: %s", test.spec, err)
+			}
+
+			info, err := vmnet1.Info(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if info.Subnet != test.subnet || info.Mask != test.mask {
+				t.Errorf("%+v: expected %s/%s, got %s/%s", test.spec, test.subnet, test.mask, info.Subnet, info.Mask)
+			}
+		}
+
+		for _, spec := range []object.NetworkSpec{
+			{Subnet: "10.50.0.1", Mask: "255.255.255.0"}, // not the network address
+			{Subnet: "10.50.0.0"},                        // neither a mask nor a CIDR
+			{Subnet: "enoent/24"},
+			{Subnet: "10.50.0.0", Mask: "255.255.255.254"},
+			{Subnet: "10.50.0.0", Mask: "255.0.255.0"},
+			{Subnet: "10.50.0.0", Mask: "enoent"},
+			{Subnet: "10.50.0.0/0"},
+			{Mask: "255.255.255.255"},
+			{Subnet: nat.Subnet + "/24"},                        // the subnet of vmnet8
+			{Subnet: "192.168.0.0/16"},                          // contains the subnet of vmnet8
+			{Subnet: nat.Subnet[:len(nat.Subnet)-1] + "128/25"}, // in the subnet of vmnet8
+			{Type: "bridged"},
+		} {
+			if err = vmnet1.Change(ctx, spec); err == nil {
+				t.Errorf("%+v: expected an error", spec)
+			}
+		}
+
+		if info, _ := vmnet1.Info(ctx); info.Subnet != "10.40.0.0" || info.Mask != "255.255.255.252" {
+			t.Errorf("expected vmnet1 to be left unchanged, got %s/%s", info.Subnet, info.Mask)
+		}
+
+		if err = network(c, "vmnet0").Change(ctx, object.NetworkSpec{Subnet: "10.60.0.0/24"}); err == nil {
+			t.Error("expected an error changing a bridged network")
+		}
+	})
+}
+
+func TestCreateNetwork(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		nat, err := network(c, "vmnet8").Info(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the overlapping subnet is refused before the network is created
+		if _, err = object.CreateNetwork(ctx, c, "vmnet2", object.NetworkSpec{Subnet: nat.Subnet + "/16"}); err == nil {
+			t.Error("expected an error creating a network overlapping vmnet8")
+		}
+
+		if _, err = object.CreateNetwork(ctx, c, "vmnet2", object.NetworkSpec{Type: "bridged"}); err == nil {
+			t.Error("expected an error creating a bridged network")
+		}
+
+		if networks, _ := c.GetAllNetworks(); len(networks.Vmnets) != 3 {
+			t.Errorf("expected 3 networks, got %+v", networks)
+		}
+
+		dhcp := false
+
+		n, err := object.CreateNetwork(ctx, c, "vmnet2", object.NetworkSpec{Type: "nat", Subnet: "10.70.0.0/24", DHCP: &dhcp})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := n.Info(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.Name != "vmnet2" || info.Type != "nat" || info.Subnet != "10.70.0.0" || info.Mask != "255.255.255.0" || info.Dhcp != "false" {
+			t.Errorf("unexpected network: %+v", info)
+		}
+
+		if _, err = object.CreateNetwork(ctx, c, "vmnet3", object.NetworkSpec{Subnet: "10.70.0.0/16"}); err == nil {
+			t.Error("expected an error creating a network overlapping vmnet2")
+		}
+	})
+}
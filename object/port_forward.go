@@ -0,0 +1,146 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// PortForwardProtocols are the protocols a NAT network can forward
+var PortForwardProtocols = []string{"tcp", "udp"}
+
+func validPort(port int) bool {
+	return port > 0 && port < 65536
+}
+
+// natInfo returns the description of the network, port forwarding is only available on NAT networks
+func (n Network) natInfo(ctx context.Context) (*model.Network, error) {
+	info, err := n.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Type != "nat" {
+		return nil, fmt.Errorf("%s is not a NAT network", info.Name)
+	}
+
+	return info, nil
+}
+
+// PortForwards returns the port forwardings of the NAT network.
+func (n Network) PortForwards(ctx context.Context) ([]model.Portforward, error) {
+	if _, err := n.natInfo(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := n.c.GetPortforwards(n.r.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.PortForwardings, nil
+}
+
+// PortForward returns the port forwarding of the host port, nil if the port is not forwarded.
+func (n Network) PortForward(ctx context.Context, protocol string, port int) (*model.Portforward, error) {
+	forwards, err := n.PortForwards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, forward := range forwards {
+		if forward.Protocol == protocol && forward.Port == port {
+			return &forward, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// AddPortForward forwards the host port to the guest address. The guest must be on the NAT network subnet
+// and the host port must not already be forwarded to another guest.
+func (n Network) AddPortForward(ctx context.Context, protocol string, port int, guestIP string, guestPort int, desc string) error {
+	protocol = strings.ToLower(protocol)
+
+	if !contains(PortForwardProtocols, protocol) {
+		return fmt.Errorf("invalid protocol %q, must be one of %s", protocol, strings.Join(PortForwardProtocols, "|"))
+	}
+
+	if !validPort(port) {
+		return fmt.Errorf("invalid host port %d", port)
+	}
+
+	if !validPort(guestPort) {
+		return fmt.Errorf("invalid guest port %d", guestPort)
+	}
+
+	ip := net.ParseIP(guestIP).To4()
+	if ip == nil {
+		return fmt.Errorf("invalid guest IPv4 address %q", guestIP)
+	}
+
+	info, err := n.natInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !inSubnet(info, ip) {
+		return fmt.Errorf("guest %s is not on the %s subnet %s/%s", guestIP, info.Name, info.Subnet, info.Mask)
+	}
+
+	current, err := n.PortForward(ctx, protocol, port)
+	if err != nil {
+		return err
+	}
+
+	if current != nil && current.Guest != nil {
+		if current.Guest.Ip == ip.String() && current.Guest.Port == guestPort {
+			return nil
+		}
+
+		return fmt.Errorf("%s port %d is already forwarded to %s:%d", protocol, port, current.Guest.Ip, current.Guest.Port)
+	}
+
+	_, err = n.c.UpdatePortforward(n.r.Value, protocol, port, &model.PortforwardParameter{
+		GuestIp:   ip.String(),
+		GuestPort: guestPort,
+		Desc:      desc,
+	})
+
+	return err
+}
+
+// RemovePortForward removes the port forwarding of the host port.
+func (n Network) RemovePortForward(ctx context.Context, protocol string, port int) error {
+	protocol = strings.ToLower(protocol)
+
+	current, err := n.PortForward(ctx, protocol, port)
+	if err != nil {
+		return err
+	}
+
+	if current == nil {
+		return fmt.Errorf("%s port %d is not forwarded on %s", protocol, port, n.r.Value)
+	}
+
+	return n.c.DeletePortforward(n.r.Value, protocol, port)
+}
@@ -38,7 +38,32 @@ func NewSearchIndex(c *vim25.Client) *SearchIndex {
 	return &s
 }
 
-// FindByDatastorePath finds a virtual machine by its location on a datastore.
+// reference returns the object for the given reference, virtual machines and networks get their inventory path set.
+func (s SearchIndex) reference(ref types.ManagedObjectReference) (Reference, error) {
+	r := NewReference(s.c, ref)
+
+	if net, ok := r.(*Network); ok {
+		net.InventoryPath = NetworkInventoryPath(ref.Value)
+	}
+
+	if vm, ok := r.(*VirtualMachine); ok {
+		vmids, err := s.c.GetAllVMs()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, vmid := range vmids {
+			if vmid.Id == ref.Value {
+				vm.InventoryPath = InventoryPath(vmid.Path)
+				break
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// FindByDatastorePath finds a virtual machine by the path of its .vmx file.
 func (s SearchIndex) FindByDatastorePath(ctx context.Context, path string) (Reference, error) {
 	req := types.FindByDatastorePath{
 		This: s.Reference(),
@@ -53,7 +78,7 @@ func (s SearchIndex) FindByDatastorePath(ctx context.Context, path string) (Refe
 	if res.Returnval == nil {
 		return nil, nil
 	}
-	return NewReference(s.c, *res.Returnval), nil
+	return s.reference(*res.Returnval)
 }
 
 // FindByDnsName finds a virtual machine by DNS name.
@@ -72,7 +97,7 @@ func (s SearchIndex) FindByDnsName(ctx context.Context, dnsName string) (Referen
 	if res.Returnval == nil {
 		return nil, nil
 	}
-	return NewReference(s.c, *res.Returnval), nil
+	return s.reference(*res.Returnval)
 }
 
 // FindByIp finds a virtual machine by IP address.
@@ -91,10 +116,10 @@ func (s SearchIndex) FindByIp(ctx context.Context, ip string) (Reference, error)
 	if res.Returnval == nil {
 		return nil, nil
 	}
-	return NewReference(s.c, *res.Returnval), nil
+	return s.reference(*res.Returnval)
 }
 
-// FindByUuid finds a virtual machine or host by UUID.
+// FindByUuid finds a virtual machine by BIOS UUID.
 func (s SearchIndex) FindByUuid(ctx context.Context, uuid string) (Reference, error) {
 	var instanceUuid bool
 
@@ -113,5 +138,5 @@ func (s SearchIndex) FindByUuid(ctx context.Context, uuid string) (Reference, er
 	if res.Returnval == nil {
 		return nil, nil
 	}
-	return NewReference(s.c, *res.Returnval), nil
+	return s.reference(*res.Returnval)
 }
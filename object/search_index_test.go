@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestSearchIndex(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		s := object.NewSearchIndex(c)
+
+		vm0 := findVM(ctx, t, c, "VM0")
+		vm1 := findVM(ctx, t, c, "VM1")
+
+		ip, err := c.GetIPAddress(vm0.Reference().Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// VM1 is found by its DHCP reservation when VMware Tools is not running
+		task, err := vm1.PowerOff(ctx)
+		wait(ctx, t, task, err)
+
+		nics, err := c.GetAllNICDevices(vm1.Reference().Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := object.NewNetwork(c, types.ManagedObjectReference{Type: "Network", Value: "vmnet8"}).Info(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reserved := net.ParseIP(info.Subnet).To4()
+		reserved[3] = 200
+
+		if _, err = c.UpdateMacToIP("vmnet8", nics.Nics[0].MacAddress, &model.MacToIpParameter{IP: reserved.String()}); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := loadVMX(ctx, t, vm1)
+		bios := cfg.Get("uuid.bios")
+		hex := strings.NewReplacer(" ", "", "-", "").Replace(bios)
+		uuid := strings.ToUpper(strings.Join([]string{hex[:8], hex[8:12], hex[12:16], hex[16:20], hex[20:]}, "-"))
+
+		vmxPath, err := vm1.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			name   string
+			find   func() (object.Reference, error)
+			expect *object.VirtualMachine
+		}{
+			{"ip", func() (object.Reference, error) { return s.FindByIp(ctx, ip.Ip) }, vm0},
+			{"reserved ip", func() (object.Reference, error) { return s.FindByIp(ctx, reserved.String()) }, vm1},
+			{"unknown ip", func() (object.Reference, error) { return s.FindByIp(ctx, "192.0.2.1") }, nil},
+			{"uuid.bios", func() (object.Reference, error) { return s.FindByUuid(ctx, bios) }, vm1},
+			{"uuid", func() (object.Reference, error) { return s.FindByUuid(ctx, uuid) }, vm1},
+			{"unknown uuid", func() (object.Reference, error) { return s.FindByUuid(ctx, "00000000-0000-0000-0000-000000000000") }, nil},
+			{"path", func() (object.Reference, error) { return s.FindByDatastorePath(ctx, vmxPath) }, vm1},
+			{"unknown path", func() (object.Reference, error) { return s.FindByDatastorePath(ctx, vmxPath+".enoent") }, nil},
+		}
+
+		for _, test := range tests {
+			ref, err := test.find()
+			if err != nil {
+				t.Fatalf("%s: %s", test.name, err)
+			}
+
+			if test.expect == nil {
+				if ref != nil {
+					t.Errorf("%s: expected no VM, got %s", test.name, ref.Reference())
+				}
+				continue
+			}
+
+			vm, ok := ref.(*object.VirtualMachine)
+			if !ok {
+				t.Errorf("%s: expected %s, got %v", test.name, test.expect.Reference(), ref)
+				continue
+			}
+
+			if vm.Reference() != test.expect.Reference() || vm.InventoryPath != test.expect.InventoryPath {
+				t.Errorf("%s: expected %s (%s), got %s (%s)", test.name, test.expect.Reference(), test.expect.InventoryPath, vm.Reference(), vm.InventoryPath)
+			}
+		}
+
+		// the guest DNS names are resolved only for the VMs reporting an IP address
+		task, err = vm0.PowerOff(ctx)
+		wait(ctx, t, task, err)
+
+		if ref, err := s.FindByDnsName(ctx, "enoent.invalid"); err != nil || ref != nil {
+			t.Errorf("expected no VM, got %v, %v", ref, err)
+		}
+
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if _, err = s.FindByDnsName(cctx, "enoent.invalid"); err != context.Canceled {
+			t.Errorf("expected %s, got %v", context.Canceled, err)
+		}
+	})
+}
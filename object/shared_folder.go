@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// SharedFolderReadWrite is the flag of a writable shared folder, the only flag supported by vmrest
+const SharedFolderReadWrite = 4
+
+func sharedFolderFlags(readOnly bool) int {
+	if readOnly {
+		return 0
+	}
+
+	return SharedFolderReadWrite
+}
+
+// sharedFolderPath returns the absolute path of a shared folder, which must be a directory of this host
+func sharedFolderPath(hostPath string) (string, error) {
+	p, err := filepath.Abs(hostPath)
+	if err != nil {
+		return "", err
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid shared folder host path: %w", err)
+	}
+
+	if !fi.IsDir() {
+		return "", fmt.Errorf("invalid shared folder host path: %s is not a directory", p)
+	}
+
+	return p, nil
+}
+
+// SharedFolders returns the shared folders of the VirtualMachine
+func (v VirtualMachine) SharedFolders(ctx context.Context) (model.SharedFolders, error) {
+	return v.c.GetAllSharedFolders(v.r.Value)
+}
+
+// SharedFolder returns the shared folder with the given name
+func (v VirtualMachine) SharedFolder(ctx context.Context, name string) (*model.SharedFolder, error) {
+	folders, err := v.SharedFolders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range folders {
+		if folders[i].FolderId == name {
+			return &folders[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("shared folder %q not found", name)
+}
+
+// AddSharedFolder shares the host directory with the guest, the name defaults to the directory name.
+// The host path must be reachable from this host.
+func (v VirtualMachine) AddSharedFolder(ctx context.Context, name, hostPath string, readOnly bool) (model.SharedFolders, error) {
+	hostPath, err := sharedFolderPath(hostPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = filepath.Base(hostPath)
+	}
+
+	return v.c.CreateSharedFolder(v.r.Value, &model.SharedFolder{
+		FolderId: name,
+		HostPath: hostPath,
+		Flags:    sharedFolderFlags(readOnly),
+	})
+}
+
+// ChangeSharedFolder changes the host path or the access of a shared folder, an empty host path or a nil readOnly are left unchanged
+func (v VirtualMachine) ChangeSharedFolder(ctx context.Context, name, hostPath string, readOnly *bool) (model.SharedFolders, error) {
+	folder, err := v.SharedFolder(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	param := model.SharedFolderParameter{
+		HostPath: folder.HostPath,
+		Flags:    folder.Flags,
+	}
+
+	if hostPath != "" {
+		if param.HostPath, err = sharedFolderPath(hostPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if readOnly != nil {
+		param.Flags = sharedFolderFlags(*readOnly)
+	}
+
+	return v.c.UpdateSharedFolder(v.r.Value, name, &param)
+}
+
+// RemoveSharedFolder stops sharing the folder with the guest, the host directory is kept
+func (v VirtualMachine) RemoveSharedFolder(ctx context.Context, name string) error {
+	return v.c.DeleteSharedFolder(v.r.Value, name)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govc sources https://github.com/vmware/govmomi/govc
+*/
+
+package object
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/progress"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// TaskCondition reports whether the operation tracked by a Task is completed.
+type TaskCondition func(ctx context.Context) (bool, error)
+
+var taskCounter int64
+
+// Task tracks a vmrest operation. vmrest requests return once the operation is accepted,
+// the Task polls the entity until the condition is met, the context is done or an error occurs.
+type Task struct {
+	Common
+
+	// Backoff configures the evaluation of the condition
+	Backoff Backoff
+
+	// Result is returned in the TaskInfo once the task succeeded, the new VM reference for a clone
+	Result types.AnyType
+
+	name      string
+	entity    types.ManagedObjectReference
+	completed TaskCondition
+}
+
+// NewTask returns a task named after the vSphere method it replaces, operating on the given entity.
+// A nil condition is a task already completed.
+func NewTask(c *vim25.Client, entity types.ManagedObjectReference, name string, completed TaskCondition) *Task {
+	ref := types.ManagedObjectReference{
+		Type:  "Task",
+		Value: fmt.Sprintf("task-%d", atomic.AddInt64(&taskCounter, 1)),
+	}
+
+	return &Task{
+		Common:    NewCommon(c, ref),
+		Backoff:   DefaultBackoff,
+		name:      name,
+		entity:    entity,
+		completed: completed,
+	}
+}
+
+type taskReport struct {
+	percentage float32
+	detail     string
+	err        error
+}
+
+func (r taskReport) Percentage() float32 {
+	return r.percentage
+}
+
+func (r taskReport) Detail() string {
+	return r.detail
+}
+
+func (r taskReport) Error() error {
+	return r.err
+}
+
+// taskFault translates an error into the closest vSphere fault
+func taskFault(err error) types.BaseMethodFault {
+	var powerState *InvalidPowerStateError
+
+	switch {
+	case errors.As(err, &powerState):
+		return &types.InvalidPowerState{
+			RequestedState: powerState.RequestedState,
+			ExistingState:  powerState.ExistingState,
+		}
+	case errors.Is(err, ErrToolsUnavailable):
+		return &types.ToolsUnavailable{}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &types.Timedout{}
+	case errors.Is(err, context.Canceled):
+		return &types.RequestCanceled{}
+	default:
+		return &types.SystemError{Reason: err.Error()}
+	}
+}
+
+func (t *Task) Wait(ctx context.Context) error {
+	_, err := t.WaitForResult(ctx, nil)
+	return err
+}
+
+// WaitForResult waits for the task to complete, progress is reported to the optional sink.
+// Failures are returned as task.Error.
+func (t *Task) WaitForResult(ctx context.Context, s ...progress.Sinker) (*types.TaskInfo, error) {
+	var sink chan<- progress.Report
+
+	if len(s) == 1 && s[0] != nil {
+		sink = s[0].Sink()
+		defer close(sink)
+	}
+
+	now := time.Now()
+	entity := t.entity
+	info := &types.TaskInfo{
+		Key:           t.r.Value,
+		Task:          t.r,
+		Name:          t.name,
+		DescriptionId: t.name,
+		Entity:        &entity,
+		State:         types.TaskInfoStateRunning,
+		QueueTime:     now,
+		StartTime:     &now,
+	}
+
+	report := func(r taskReport) {
+		if sink != nil {
+			sink <- r
+		}
+	}
+
+	err := t.Backoff.Poll(ctx, func(ctx context.Context) (bool, error) {
+		if t.completed == nil {
+			return true, nil
+		}
+
+		done, err := t.completed(ctx)
+		if err == nil && !done {
+			report(taskReport{detail: fmt.Sprintf("%s elapsed", time.Since(now).Round(time.Second))})
+		}
+
+		return done, err
+	})
+
+	complete := time.Now()
+	info.CompleteTime = &complete
+
+	if err != nil {
+		info.State = types.TaskInfoStateError
+		info.Error = &types.LocalizedMethodFault{
+			Fault:            taskFault(err),
+			LocalizedMessage: err.Error(),
+		}
+
+		report(taskReport{err: err})
+
+		return info, task.Error{
+			LocalizedMethodFault: info.Error,
+			Description: &types.LocalizableMessage{
+				Key:     t.name,
+				Message: fmt.Sprintf("%s on %s", t.name, t.entity.Value),
+			},
+		}
+	}
+
+	info.State = types.TaskInfoStateSuccess
+	info.Progress = 100
+	info.Result = t.Result
+
+	report(taskReport{percentage: 100})
+
+	return info, nil
+}
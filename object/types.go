@@ -36,6 +36,7 @@ func NewReference(c *vim25.Client, e types.ManagedObjectReference) Reference {
 	case "Network":
 		return NewNetwork(c, e)
 	default:
-		panic("Unknown managed entity: " + e.Type)
+		r := NewCommon(c, e)
+		return &r
 	}
 }
@@ -20,10 +20,17 @@ package object
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/Fred78290/govmrest/vim25"
-	"github.com/Fred78290/govmrest/vim25/methods"
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/Fred78290/govmrest/vmx"
 	"github.com/Fred78290/vmrest-go-client/client/model"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
@@ -50,79 +57,581 @@ func removeKey(l *[]int, key int) {
 	}
 }
 
+// VirtualMachinePowerStatePaused is the vmrest paused state, it has no vSphere equivalent.
+const VirtualMachinePowerStatePaused = types.VirtualMachinePowerState("paused")
+
+var (
+	ErrToolsUnavailable = errors.New("VMware Tools is not running in the guest")
+)
+
+// InvalidPowerStateError is returned when a power operation is not allowed in the current power state.
+type InvalidPowerStateError struct {
+	RequestedState types.VirtualMachinePowerState
+	ExistingState  types.VirtualMachinePowerState
+}
+
+func (e *InvalidPowerStateError) Error() string {
+	if e.AlreadyInState() {
+		return fmt.Sprintf("the virtual machine is already in the requested state (%s)", e.ExistingState)
+	}
+
+	return fmt.Sprintf("the attempted operation cannot be performed in the current state (%s)", e.ExistingState)
+}
+
+// AlreadyInState returns true if the virtual machine is already in the requested power state.
+func (e *InvalidPowerStateError) AlreadyInState() bool {
+	return e.RequestedState == e.ExistingState
+}
+
+// powerState translates a vmrest power state
+func powerState(state string) types.VirtualMachinePowerState {
+	switch state {
+	case "poweredOn":
+		return types.VirtualMachinePowerStatePoweredOn
+	case "poweredOff":
+		return types.VirtualMachinePowerStatePoweredOff
+	case "suspended":
+		return types.VirtualMachinePowerStateSuspended
+	case "paused":
+		return VirtualMachinePowerStatePaused
+	default:
+		return types.VirtualMachinePowerState(state)
+	}
+}
+
 func (v VirtualMachine) PowerState(ctx context.Context) (types.VirtualMachinePowerState, error) {
+	res, err := v.c.GetPowerState(v.r.Value)
+	if err != nil {
+		return "", err
+	}
 
-	return types.VirtualMachinePowerStatePoweredOn, nil
+	return powerState(res.PowerState), nil
 }
 
-func (v VirtualMachine) PowerOn(ctx context.Context) error {
-	return nil
+// powerStateCondition is satisfied once the virtual machine reaches the given power state
+func (v VirtualMachine) powerStateCondition(state types.VirtualMachinePowerState) TaskCondition {
+	return func(ctx context.Context) (bool, error) {
+		s, err := v.PowerState(ctx)
+		return s == state, err
+	}
 }
 
-func (v VirtualMachine) PowerOff(ctx context.Context) error {
-	return nil
+// changePowerState applies the vmrest power operation if the current power state is one of from,
+// the returned task waits for the requested state.
+func (v VirtualMachine) changePowerState(ctx context.Context, name string, op model.VmPowerOperation, requested types.VirtualMachinePowerState, from ...types.VirtualMachinePowerState) (*Task, error) {
+	state, err := v.PowerState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, s := range from {
+		if s == state {
+			allowed = true
+			break
+		}
+	}
+
+	if !allowed {
+		return nil, &InvalidPowerStateError{
+			RequestedState: requested,
+			ExistingState:  state,
+		}
+	}
+
+	if _, err = v.c.ChangePowerState(v.r.Value, op); err != nil {
+		return nil, err
+	}
+
+	return NewTask(v.c, v.r, name, v.powerStateCondition(requested)), nil
 }
 
-func (v VirtualMachine) Reset(ctx context.Context) error {
-	return nil
+func (v VirtualMachine) PowerOn(ctx context.Context) (*Task, error) {
+	state, err := v.PowerState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == VirtualMachinePowerStatePaused {
+		return v.Unpause(ctx)
+	}
+
+	return v.changePowerState(ctx, "PowerOnVM_Task", model.VM_ON, types.VirtualMachinePowerStatePoweredOn,
+		types.VirtualMachinePowerStatePoweredOff, types.VirtualMachinePowerStateSuspended)
 }
 
-func (v VirtualMachine) Suspend(ctx context.Context) error {
-	return nil
+func (v VirtualMachine) PowerOff(ctx context.Context) (*Task, error) {
+	return v.changePowerState(ctx, "PowerOffVM_Task", model.VM_OFF, types.VirtualMachinePowerStatePoweredOff,
+		types.VirtualMachinePowerStatePoweredOn, VirtualMachinePowerStatePaused, types.VirtualMachinePowerStateSuspended)
+}
+
+// Reset is a hard power off followed by a power on, vmrest has no reset operation.
+func (v VirtualMachine) Reset(ctx context.Context) (*Task, error) {
+	task, err := v.changePowerState(ctx, "ResetVM_Task", model.VM_OFF, types.VirtualMachinePowerStatePoweredOn,
+		types.VirtualMachinePowerStatePoweredOn, VirtualMachinePowerStatePaused)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = v.WaitForPowerState(ctx, types.VirtualMachinePowerStatePoweredOff); err != nil {
+		return nil, err
+	}
+
+	if _, err = v.c.ChangePowerState(v.r.Value, model.VM_ON); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (v VirtualMachine) Suspend(ctx context.Context) (*Task, error) {
+	return v.changePowerState(ctx, "SuspendVM_Task", model.VM_SUSPEND, types.VirtualMachinePowerStateSuspended,
+		types.VirtualMachinePowerStatePoweredOn)
 }
 
+// Pause freezes the virtual machine execution without saving its state to disk.
+func (v VirtualMachine) Pause(ctx context.Context) (*Task, error) {
+	return v.changePowerState(ctx, "PauseVM_Task", model.VM_PAUSE, VirtualMachinePowerStatePaused,
+		types.VirtualMachinePowerStatePoweredOn)
+}
+
+// Unpause resumes a paused virtual machine.
+func (v VirtualMachine) Unpause(ctx context.Context) (*Task, error) {
+	return v.changePowerState(ctx, "UnpauseVM_Task", model.VM_UNPAUSE, types.VirtualMachinePowerStatePoweredOn,
+		VirtualMachinePowerStatePaused)
+}
+
+// ShutdownGuest asks the guest OS to shutdown, VMware Tools must be running.
 func (v VirtualMachine) ShutdownGuest(ctx context.Context) error {
-	return nil
+	_, err := v.changePowerState(ctx, "ShutdownGuest", model.VM_SHUTDOWN, types.VirtualMachinePowerStatePoweredOff,
+		types.VirtualMachinePowerStatePoweredOn)
+	if err != nil {
+		if _, ok := err.(*InvalidPowerStateError); !ok {
+			if running, _ := v.IsToolsRunning(ctx); !running {
+				return ErrToolsUnavailable
+			}
+		}
+	}
+
+	return err
 }
 
+// RebootGuest shutdowns the guest OS and powers on the virtual machine once powered off,
+// vmrest has no reboot operation.
 func (v VirtualMachine) RebootGuest(ctx context.Context) error {
-	return nil
+	state, err := v.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if state != types.VirtualMachinePowerStatePoweredOn {
+		return &InvalidPowerStateError{
+			RequestedState: types.VirtualMachinePowerStatePoweredOn,
+			ExistingState:  state,
+		}
+	}
+
+	if err = v.ShutdownGuest(ctx); err != nil {
+		return err
+	}
+
+	if err = v.WaitForPowerState(ctx, types.VirtualMachinePowerStatePoweredOff); err != nil {
+		return err
+	}
+
+	_, err = v.c.ChangePowerState(v.r.Value, model.VM_ON)
+
+	return err
 }
 
-func (v VirtualMachine) Destroy(ctx context.Context) error {
-	return nil
+// vmRegisteredCondition is satisfied once the virtual machine with the given id is (un)registered
+func (v VirtualMachine) vmRegisteredCondition(id string, registered bool) TaskCondition {
+	return func(ctx context.Context) (bool, error) {
+		vmids, err := v.c.GetAllVMs()
+		if err != nil {
+			return false, err
+		}
+
+		for _, vmid := range vmids {
+			if vmid.Id == id {
+				return registered, nil
+			}
+		}
+
+		return !registered, nil
+	}
 }
 
-func (v VirtualMachine) Clone(ctx context.Context, folder *Folder, name string, config types.VirtualMachineCloneSpec) error {
-	return nil
+// Destroy deletes the virtual machine and its files, the task completes once it is removed from the inventory.
+// The parent of linked clones cannot be destroyed while they exist.
+func (v VirtualMachine) Destroy(ctx context.Context) (*Task, error) {
+	var vmxPath, parentPath string
+
+	// linked clones are only known when the virtual machine files are local
+	if p, err := v.localVMX(ctx); err == nil {
+		clones, err := linkedClones(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(clones) != 0 {
+			return nil, fmt.Errorf("%w: %s", ErrLinkedClones, strings.Join(clones, ", "))
+		}
+
+		if cfg, err := vmx.Load(p); err == nil {
+			vmxPath, parentPath = p, cfg.Get("cloneOf0")
+		}
+	}
+
+	if err := v.c.DeleteVM(v.r.Value); err != nil {
+		return nil, err
+	}
+
+	if parentPath != "" {
+		_ = forgetLinkedClone(parentPath, vmxPath)
+	}
+
+	return NewTask(v.c, v.r, "Destroy_Task", v.vmRegisteredCondition(v.r.Value, false)), nil
+}
+
+// discard destroys a new virtual machine that could not be set up, so creating it can be retried.
+// The cause of the failure is returned, along with the reason the virtual machine could not be destroyed.
+func (v VirtualMachine) discard(ctx context.Context, cause error) error {
+	vmxPath, _ := v.localVMX(ctx)
+
+	_, _ = v.c.ChangePowerState(v.r.Value, model.VM_OFF)
+
+	task, err := v.Destroy(ctx)
+	if err == nil {
+		err = task.Wait(ctx)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s, destroying %s: %s", cause, v.r.Value, err)
+	}
+
+	if vmxPath != "" {
+		_ = os.RemoveAll(filepath.Dir(vmxPath))
+	}
+
+	return cause
+}
+
+// Clone creates a copy of the virtual machine named name, the task result is the new VM reference.
+// The folder is ignored, vmrest has no notion of folders.
+// The config spec is applied to the clone with Reconfigure, the clone is then powered on if requested.
+// A linked clone is created when the disk move type of the location is createNewChildDiskBacking.
+func (v VirtualMachine) Clone(ctx context.Context, folder *Folder, name string, config types.VirtualMachineCloneSpec) (*Task, error) {
+	var id string
+
+	if config.Location.DiskMoveType == string(types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking) {
+		linked, err := v.linkedClone(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		id = linked
+	} else {
+		info, err := v.c.CreateVM(&model.VmCloneParameter{
+			Name:     name,
+			ParentId: v.r.Value,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		id = info.Id
+	}
+
+	clone := NewVirtualMachine(v.c, types.ManagedObjectReference{
+		Type:  "VirtualMachine",
+		Value: id,
+	})
+
+	if config.Config != nil {
+		if _, err := clone.Reconfigure(ctx, *config.Config); err != nil {
+			return nil, clone.discard(ctx, err)
+		}
+	}
+
+	completed := v.vmRegisteredCondition(id, true)
+
+	if config.PowerOn {
+		if _, err := v.c.ChangePowerState(id, model.VM_ON); err != nil {
+			return nil, clone.discard(ctx, err)
+		}
+
+		registered, poweredOn := completed, clone.powerStateCondition(types.VirtualMachinePowerStatePoweredOn)
+
+		completed = func(ctx context.Context) (bool, error) {
+			if done, err := registered(ctx); !done || err != nil {
+				return done, err
+			}
+
+			return poweredOn(ctx)
+		}
+	}
+
+	task := NewTask(v.c, v.r, "CloneVM_Task", completed)
+	task.Result = clone.Reference()
+
+	return task, nil
 }
 
 func (v VirtualMachine) Customize(ctx context.Context, spec types.CustomizationSpec) error {
 	return nil
 }
 
-func (v VirtualMachine) Reconfigure(ctx context.Context, config types.VirtualMachineConfigSpec) error {
-	return nil
+// poweredOffVMX returns the path of the .vmx file of a powered off virtual machine whose files are reachable from this host
+func (v VirtualMachine) poweredOffVMX(ctx context.Context) (string, error) {
+	state, err := v.PowerState(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if state != types.VirtualMachinePowerStatePoweredOff {
+		return "", &InvalidPowerStateError{
+			RequestedState: types.VirtualMachinePowerStatePoweredOff,
+			ExistingState:  state,
+		}
+	}
+
+	return v.localVMX(ctx)
+}
+
+// editableVMX returns the path of the .vmx file if it can be edited locally,
+// the virtual machine must be powered off and its files reachable from this host.
+func (v VirtualMachine) editableVMX(ctx context.Context) (string, bool) {
+	vmxPath, err := v.poweredOffVMX(ctx)
+
+	return vmxPath, err == nil
+}
+
+// Reconfigure applies the number of CPUs and the memory size through the vmrest VM settings.
+// The other supported fields, ExtraConfig and the device changes are written to the .vmx file when the virtual
+// machine is powered off and its files are local, the fields are applied through the vmrest config params and
+// the device changes rejected otherwise. The fields Workstation cannot honour, such as latency sensitivity or
+// resource allocations, are rejected.
+func (v VirtualMachine) Reconfigure(ctx context.Context, config types.VirtualMachineConfigSpec) (*Task, error) {
+	settings := config
+	settings.NumCPUs, settings.MemoryMB = 0, 0
+	settings.DeviceChange = nil
+
+	if fields := vmx.Unsupported(settings); len(fields) != 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotSupported, strings.Join(fields, ", "))
+	}
+
+	if config.NumCPUs < 0 {
+		return nil, fmt.Errorf("invalid number of CPUs %d", config.NumCPUs)
+	}
+
+	if config.MemoryMB < 0 || config.MemoryMB%4 != 0 {
+		return nil, fmt.Errorf("invalid memory size %dMB, must be a multiple of 4MB", config.MemoryMB)
+	}
+
+	// the vmx is checked once, a virtual machine powered on meanwhile fails to save rather than dropping the device changes
+	vmxPath, err := v.poweredOffVMX(ctx)
+	if err == nil {
+		cfg, err := vmx.Load(vmxPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = cfg.ApplyConfigSpec(settings); err != nil {
+			return nil, err
+		}
+
+		w := newVMXDeviceConfig(vmxPath, cfg)
+
+		if err = w.apply(config.DeviceChange); err != nil {
+			return nil, err
+		}
+
+		// the vmx changes are validated before vmrest writes the CPUs and memory to the vmx,
+		// the saved vmx keeps the values written by vmrest and they are restored if it cannot be saved
+		var restore model.VmParameter
+
+		if config.NumCPUs != 0 || config.MemoryMB != 0 {
+			restore.Processors, _ = strconv.Atoi(cfg.Get("numvcpus"))
+			restore.Memory, _ = strconv.Atoi(cfg.Get("memsize"))
+
+			if err = v.updateVM(config); err != nil {
+				return nil, err
+			}
+
+			if err = cfg.ApplyConfigSpec(types.VirtualMachineConfigSpec{NumCPUs: config.NumCPUs, MemoryMB: config.MemoryMB}); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = w.save(); err != nil {
+			if restore.Processors > 0 || restore.Memory > 0 {
+				_, _ = v.c.UpdateVM(v.r.Value, &restore)
+			}
+
+			return nil, err
+		}
+
+		return NewTask(v.c, v.r, "ReconfigVM_Task", nil), nil
+	}
+
+	if len(config.DeviceChange) != 0 {
+		return nil, err
+	}
+
+	// the settings applied to an empty file are the config params to send
+	params := vmx.New()
+	if err := params.ApplyConfigSpec(settings); err != nil {
+		return nil, err
+	}
+
+	for _, option := range config.ExtraConfig {
+		if o := option.GetOptionValue(); !params.Has(o.Key) {
+			return nil, fmt.Errorf("%w: removing %s requires the virtual machine to be powered off and its files reachable from this host", ErrNotSupported, o.Key)
+		}
+	}
+
+	if err := v.updateVM(config); err != nil {
+		return nil, err
+	}
+
+	for _, e := range params.Entries() {
+		if _, err := v.c.ConfigVMParams(v.r.Value, &model.ConfigVmParamsParameter{Name: e.Key, Value: e.Value}); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewTask(v.c, v.r, "ReconfigVM_Task", nil), nil
+}
+
+// updateVM applies the number of CPUs and the memory size of the config spec through the vmrest VM settings
+func (v VirtualMachine) updateVM(config types.VirtualMachineConfigSpec) error {
+	if config.NumCPUs == 0 && config.MemoryMB == 0 {
+		return nil
+	}
+
+	_, err := v.c.UpdateVM(v.r.Value, &model.VmParameter{
+		Processors: int(config.NumCPUs),
+		Memory:     int(config.MemoryMB),
+	})
+
+	return err
+}
+
+// WaitForIPOptions configures VirtualMachine.WaitForIPWithOptions
+type WaitForIPOptions struct {
+	Backoff
+
+	// V4 waits for an IPv4 address
+	V4 bool
+
+	// DHCP falls back to the vmnet MAC to IP reservations and DHCP leases
+	// when VMware Tools is not reporting the guest IP address.
+	DHCP bool
 }
 
 // WaitForIP waits for the VM guest.ipAddress property to report an IP address.
 // Waits for an IPv4 address if the v4 param is true.
 func (v VirtualMachine) WaitForIP(ctx context.Context, v4 ...bool) (string, error) {
+	return v.WaitForIPWithOptions(ctx, WaitForIPOptions{
+		Backoff: DefaultBackoff,
+		V4:      len(v4) == 1 && v4[0],
+	})
+}
+
+// WaitForIPWithOptions waits for the guest IP address reported by VMware Tools,
+// or the address leased by the vmnet DHCP server when the DHCP option is set.
+func (v VirtualMachine) WaitForIPWithOptions(ctx context.Context, options WaitForIPOptions) (string, error) {
 	var ip string
 
+	valid := func(addr string) bool {
+		parsed := net.ParseIP(addr)
+		return parsed != nil && (!options.V4 || parsed.To4() != nil)
+	}
+
+	err := options.Backoff.Poll(ctx, func(ctx context.Context) (bool, error) {
+		res, err := v.c.GetIPAddress(v.r.Value)
+		if err == nil {
+			if valid(res.Ip) {
+				ip = res.Ip
+				return true, nil
+			}
+
+			// Look for an address of the requested family in the NICs IP stacks
+			if stacks, err := v.c.GetNicInfo(v.r.Value); err == nil {
+				for _, nic := range stacks.Nics {
+					for _, cidr := range nic.Ip {
+						if addr, _, err := net.ParseCIDR(cidr); err == nil && valid(addr.String()) {
+							ip = addr.String()
+							return true, nil
+						}
+					}
+				}
+			}
+
+			return false, nil
+		}
+
+		if options.DHCP {
+			if ip = v.dhcpIP(ctx); valid(ip) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
 	return ip, nil
 }
 
-// Device returns the VirtualMachine's config.hardware.device property.
-func (v VirtualMachine) Device(ctx context.Context) (VirtualDeviceList, error) {
-	var o mo.VirtualMachine
+// dhcpIP returns the address reserved or leased by the vmnet DHCP server to the first NIC that has one.
+func (v VirtualMachine) dhcpIP(ctx context.Context) string {
+	if state, err := v.PowerState(ctx); err != nil || state != types.VirtualMachinePowerStatePoweredOn {
+		return ""
+	}
+
+	nics, err := v.c.GetAllNICDevices(v.r.Value)
+	if err != nil {
+		return ""
+	}
+
+	for _, nic := range nics.Nics {
+		if mactoips, err := v.c.GetMACToIPs(nic.Vmnet); err == nil {
+			for _, mactoip := range mactoips.Mactoips {
+				if sameMAC(mactoip.Mac, nic.MacAddress) {
+					return mactoip.Ip
+				}
+			}
+		}
+
+		for _, name := range DHCPLeaseFiles(nic.Vmnet) {
+			if ip := dhcpLease(name, nic.MacAddress); ip != "" {
+				return ip
+			}
+		}
+	}
 
-	err := v.Properties(ctx, v.Reference(), []string{"config.hardware.device", "summary.runtime.connectionState"}, &o)
+	return ""
+}
+
+// Device returns the virtual devices defined in the VirtualMachine's vmx file,
+// which must be reachable from this host as vmrest has no property collector.
+func (v VirtualMachine) Device(ctx context.Context) (VirtualDeviceList, error) {
+	vmxPath, err := v.localVMX(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Quoting the SDK doc:
-	//   The virtual machine configuration is not guaranteed to be available.
-	//   For example, the configuration information would be unavailable if the server
-	//   is unable to access the virtual machine files on disk, and is often also unavailable
-	//   during the initial phases of virtual machine creation.
-	if o.Config == nil {
-		return nil, fmt.Errorf("%s Config is not available, connectionState=%s",
-			v.Reference(), o.Summary.Runtime.ConnectionState)
+	cfg, err := vmx.Load(vmxPath)
+	if err != nil {
+		return nil, err
 	}
 
-	return VirtualDeviceList(o.Config.Hardware.Device), nil
+	return readVMXDevices(vmxPath, cfg), nil
 }
 
 func diskFileOperation(op types.VirtualDeviceConfigSpecOperation, fop types.VirtualDeviceConfigSpecFileOperation, device types.BaseVirtualDevice) types.VirtualDeviceConfigSpecFileOperation {
@@ -184,39 +693,72 @@ func (v VirtualMachine) RemoveDevice(ctx context.Context, keepFiles bool, device
 	return v.configureDevice(ctx, types.VirtualDeviceConfigSpecOperationRemove, fop, device...)
 }
 
-// AttachDisk attaches the given disk to the VirtualMachine
-func (v VirtualMachine) AttachDisk(ctx context.Context, id string, datastore *Datastore, controllerKey int32, unitNumber int32) error {
-	req := types.AttachDisk_Task{
-		This:          v.Reference(),
-		DiskId:        types.ID{Id: id},
-		Datastore:     datastore.Reference(),
-		ControllerKey: controllerKey,
-		UnitNumber:    &unitNumber,
+// AttachDisk attaches the existing disk file name to the controller of the VirtualMachine,
+// a negative unit number picks the first free unit of the controller.
+func (v VirtualMachine) AttachDisk(ctx context.Context, name string, controllerKey int32, unitNumber int32) error {
+	devices, err := v.Device(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller, ok := devices.FindByKey(controllerKey).(types.BaseVirtualController)
+	if !ok {
+		return fmt.Errorf("controller %d not found", controllerKey)
+	}
+
+	disk := devices.CreateDisk(controller, name)
+	if unitNumber >= 0 {
+		*disk.UnitNumber = unitNumber
 	}
 
-	res, err := methods.AttachDisk_Task(ctx, v.c, &req)
+	return v.AddDevice(ctx, disk)
+}
+
+// DetachDisk detaches the disk device name from the VirtualMachine, the disk file is kept.
+func (v VirtualMachine) DetachDisk(ctx context.Context, name string) error {
+	devices, err := v.Device(ctx)
 	if err != nil {
 		return err
 	}
 
-	task := NewTask(v.c, res.Returnval)
-	return task.Wait(ctx)
+	disk, ok := devices.Find(name).(*types.VirtualDisk)
+	if !ok {
+		return fmt.Errorf("disk '%s' not found", name)
+	}
+
+	return v.RemoveDevice(ctx, true, disk)
 }
 
-// DetachDisk detaches the given disk from the VirtualMachine
-func (v VirtualMachine) DetachDisk(ctx context.Context, id string) error {
-	req := types.DetachDisk_Task{
-		This:   v.Reference(),
-		DiskId: types.ID{Id: id},
+// ExtendDisk grows the capacity of a disk of the VirtualMachine, which must be powered off.
+// The capacity is in bytes, the disk file is rewritten locally. The disks of a virtual machine with linked clones cannot be extended.
+func (v VirtualMachine) ExtendDisk(ctx context.Context, disk *types.VirtualDisk, capacity int64) error {
+	vmxPath, err := v.poweredOffVMX(ctx)
+	if err != nil {
+		return err
 	}
 
-	res, err := methods.DetachDisk_Task(ctx, v.c, &req)
+	clones, err := linkedClones(vmxPath)
 	if err != nil {
 		return err
 	}
 
-	task := NewTask(v.c, res.Returnval)
-	return task.Wait(ctx)
+	if len(clones) != 0 {
+		return fmt.Errorf("%w: %s", ErrLinkedClones, strings.Join(clones, ", "))
+	}
+
+	backing, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	if !ok {
+		return fmt.Errorf("%w: %T disk backing", ErrNotSupported, disk.Backing)
+	}
+
+	if err = vmdk.Extend(backing.FileName, capacity); err != nil {
+		return err
+	}
+
+	disk.CapacityInBytes = (capacity + vmdk.SectorSize - 1) / vmdk.SectorSize * vmdk.SectorSize
+	disk.CapacityInKB = disk.CapacityInBytes / 1024
+
+	return nil
 }
 
 // BootOptions returns the VirtualMachine's config.bootOptions property.
@@ -232,24 +774,34 @@ func (v VirtualMachine) SetBootOptions(ctx context.Context, options *types.Virtu
 
 	spec.BootOptions = options
 
-	return v.Reconfigure(ctx, spec)
+	task, err := v.Reconfigure(ctx, spec)
+	if err != nil {
+		return err
+	}
+
+	return task.Wait(ctx)
 }
 
 // IsToolsRunning returns true if VMware Tools is currently running in the guest OS, and false otherwise.
+// vmrest does not expose the tools status, VMware Tools is running when the guest IP address is reported.
 func (v VirtualMachine) IsToolsRunning(ctx context.Context) (bool, error) {
-	var o mo.VirtualMachine
+	state, err := v.PowerState(ctx)
+	if err != nil {
+		return false, err
+	}
 
-	return o.Guest.ToolsRunningStatus == string(types.VirtualMachineToolsRunningStatusGuestToolsRunning), nil
-}
+	if state != types.VirtualMachinePowerStatePoweredOn {
+		return false, nil
+	}
 
-// Wait for the VirtualMachine to change to the desired power state.
-func (v VirtualMachine) WaitForPowerState(ctx context.Context, state types.VirtualMachinePowerState) error {
+	_, err = v.c.GetIPAddress(v.r.Value)
 
-	return nil
+	return err == nil, nil
 }
 
-func (v VirtualMachine) Unregister(ctx context.Context) error {
-	return nil
+// Wait for the VirtualMachine to change to the desired power state.
+func (v VirtualMachine) WaitForPowerState(ctx context.Context, state types.VirtualMachinePowerState) error {
+	return DefaultBackoff.Poll(ctx, v.powerStateCondition(state))
 }
 
 // QueryEnvironmentBrowser is a helper to get the environmentBrowser property.
@@ -264,5 +816,32 @@ func (v VirtualMachine) UpgradeVM(ctx context.Context, version string) error {
 // UUID is a helper to get the UUID of the VirtualMachine managed object.
 // This method returns an empty string if an error occurs when retrieving UUID from the VirtualMachine object.
 func (v VirtualMachine) UUID(ctx context.Context) string {
-	return ""
+	param, err := v.c.GetVMParams(v.r.Value, "uuid.bios")
+	if err != nil {
+		return ""
+	}
+
+	return vmx.UUID(param.Value)
+}
+
+// ExtraConfig returns the settings of the vmx file, which must be reachable from this host.
+func (v VirtualMachine) ExtraConfig(ctx context.Context) ([]types.BaseOptionValue, error) {
+	vmxPath, err := v.localVMX(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := vmx.Load(vmxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := cfg.Entries()
+	options := make([]types.BaseOptionValue, 0, len(entries))
+
+	for _, e := range entries {
+		options = append(options, &types.OptionValue{Key: e.Key, Value: e.Value})
+	}
+
+	return options, nil
 }
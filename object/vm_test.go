@@ -0,0 +1,761 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func findVM(ctx context.Context, t *testing.T, c *vim25.Client, name string) *object.VirtualMachine {
+	t.Helper()
+
+	vm, err := find.NewFinder(c).VirtualMachine(ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return vm
+}
+
+func wait(ctx context.Context, t *testing.T, task *object.Task, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = task.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func powerState(ctx context.Context, t *testing.T, vm *object.VirtualMachine, expect types.VirtualMachinePowerState) {
+	t.Helper()
+
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state != expect {
+		t.Errorf("expected %s, got %s", expect, state)
+	}
+}
+
+func loadVMX(ctx context.Context, t *testing.T, vm *object.VirtualMachine) *vmx.File {
+	t.Helper()
+
+	vmxPath, err := vm.VmxPath(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := vmx.Load(vmxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg
+}
+
+func TestVirtualMachinePower(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM0")
+
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOn)
+
+		_, err := vm.PowerOn(ctx)
+
+		var state *object.InvalidPowerStateError
+		if !errors.As(err, &state) || !state.AlreadyInState() {
+			t.Errorf("expected InvalidPowerStateError, got %v", err)
+		}
+
+		task, err := vm.Suspend(ctx)
+		wait(ctx, t, task, err)
+		powerState(ctx, t, vm, types.VirtualMachinePowerStateSuspended)
+
+		task, err = vm.PowerOn(ctx)
+		wait(ctx, t, task, err)
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOn)
+
+		task, err = vm.Pause(ctx)
+		wait(ctx, t, task, err)
+		powerState(ctx, t, vm, object.VirtualMachinePowerStatePaused)
+
+		// powering on a paused VM resumes it
+		task, err = vm.PowerOn(ctx)
+		wait(ctx, t, task, err)
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOn)
+
+		task, err = vm.Reset(ctx)
+		wait(ctx, t, task, err)
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOn)
+
+		if err = vm.RebootGuest(ctx); err != nil {
+			t.Fatal(err)
+		}
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOn)
+
+		if err = vm.ShutdownGuest(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = vm.WaitForPowerState(ctx, types.VirtualMachinePowerStatePoweredOff); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = vm.Suspend(ctx); !errors.As(err, &state) || state.AlreadyInState() {
+			t.Errorf("expected InvalidPowerStateError, got %v", err)
+		}
+
+		if err = vm.ShutdownGuest(ctx); !errors.As(err, &state) {
+			t.Errorf("expected InvalidPowerStateError, got %v", err)
+		}
+
+		task, err = vm.PowerOn(ctx)
+		wait(ctx, t, task, err)
+
+		ip, err := vm.WaitForIP(ctx, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ip == "" {
+			t.Error("expected an IP address")
+		}
+	})
+}
+
+func TestTask(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM1")
+
+		task, err := vm.PowerOff(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		info, err := task.WaitForResult(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if info.State != types.TaskInfoStateSuccess || info.Name != "PowerOffVM_Task" {
+			t.Errorf("unexpected task info: %s %s", info.Name, info.State)
+		}
+
+		if info.Entity == nil || *info.Entity != vm.Reference() {
+			t.Errorf("unexpected task entity: %v", info.Entity)
+		}
+
+		// a nil condition is a completed task
+		info, err = object.NewTask(c, vm.Reference(), "Noop", nil).WaitForResult(ctx)
+		if err != nil || info.State != types.TaskInfoStateSuccess {
+			t.Errorf("unexpected task result: %v %v", info, err)
+		}
+
+		fault := errors.New("fault")
+		task = object.NewTask(c, vm.Reference(), "Fail", func(context.Context) (bool, error) {
+			return false, fault
+		})
+
+		info, err = task.WaitForResult(ctx)
+		if err == nil || info.State != types.TaskInfoStateError {
+			t.Errorf("expected a task error, got %v", err)
+		}
+
+		task = object.NewTask(c, vm.Reference(), "Never", func(context.Context) (bool, error) {
+			return false, nil
+		})
+
+		cancel, stop := context.WithCancel(ctx)
+		stop()
+
+		if err = task.Wait(cancel); err == nil {
+			t.Error("expected a cancelled task")
+		}
+	})
+}
+
+func TestVirtualMachineClone(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM0")
+
+		task, err := vm.Clone(ctx, nil, "VM2", types.VirtualMachineCloneSpec{
+			Config: &types.VirtualMachineConfigSpec{
+				Annotation: "clone of VM0",
+			},
+			PowerOn: true,
+		})
+		wait(ctx, t, task, err)
+
+		clone := findVM(ctx, t, c, "VM2")
+		if task.Result != clone.Reference() {
+			t.Errorf("expected %s, got %v", clone.Reference(), task.Result)
+		}
+
+		powerState(ctx, t, clone, types.VirtualMachinePowerStatePoweredOn)
+
+		if _, err = vm.Clone(ctx, nil, "VM2", types.VirtualMachineCloneSpec{}); err == nil {
+			t.Error("expected an error cloning to an existing VM")
+		}
+
+		// a clone that cannot be configured is destroyed, so the clone can be retried
+		if _, err = vm.Clone(ctx, nil, "VM4", types.VirtualMachineCloneSpec{
+			Config: &types.VirtualMachineConfigSpec{MemoryMB: 1023},
+		}); err == nil {
+			t.Error("expected an error configuring an invalid memory size")
+		}
+
+		if _, err = find.NewFinder(c).VirtualMachine(ctx, "VM4"); err == nil {
+			t.Error("expected VM4 to be destroyed")
+		}
+
+		task, err = vm.Clone(ctx, nil, "VM4", types.VirtualMachineCloneSpec{})
+		wait(ctx, t, task, err)
+
+		// a linked clone requires a powered off parent to take the first snapshot
+		if _, err = vm.Clone(ctx, nil, "VM3", types.VirtualMachineCloneSpec{
+			Location: types.VirtualMachineRelocateSpec{
+				DiskMoveType: string(types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking),
+			},
+		}); err == nil {
+			t.Error("expected an InvalidPowerStateError")
+		}
+
+		task, err = vm.PowerOff(ctx)
+		wait(ctx, t, task, err)
+
+		task, err = vm.Clone(ctx, nil, "VM3", types.VirtualMachineCloneSpec{
+			Location: types.VirtualMachineRelocateSpec{
+				DiskMoveType: string(types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking),
+			},
+		})
+		wait(ctx, t, task, err)
+
+		devices, err := findVM(ctx, t, c, "VM3").Device(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		disks := devices.SelectByType((*types.VirtualDisk)(nil))
+		if len(disks) != 1 {
+			t.Fatalf("expected 1 disk, got %d", len(disks))
+		}
+
+		backing := disks[0].GetVirtualDevice().Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+		if backing.Parent == nil {
+			t.Error("expected a child disk")
+		}
+
+		// the parent of a linked clone cannot be destroyed, nor its disks extended
+		if _, err = vm.Destroy(ctx); !errors.Is(err, object.ErrLinkedClones) {
+			t.Errorf("expected ErrLinkedClones, got %v", err)
+		}
+
+		if devices, err = vm.Device(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		disk := devices.SelectByType((*types.VirtualDisk)(nil))[0].(*types.VirtualDisk)
+		if err = vm.ExtendDisk(ctx, disk, 2*disk.CapacityInBytes); !errors.Is(err, object.ErrLinkedClones) {
+			t.Errorf("expected ErrLinkedClones, got %v", err)
+		}
+	})
+}
+
+func TestVirtualMachineDestroy(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM1")
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = vm.Destroy(ctx); err == nil {
+			t.Error("expected an error destroying a powered on VM")
+		}
+
+		task, err := vm.PowerOff(ctx)
+		wait(ctx, t, task, err)
+
+		task, err = vm.Destroy(ctx)
+		wait(ctx, t, task, err)
+
+		if _, err = find.NewFinder(c).VirtualMachine(ctx, "VM1"); err == nil {
+			t.Error("expected VM1 to be removed")
+		}
+
+		if _, err = os.Stat(filepath.Dir(vmxPath)); !os.IsNotExist(err) {
+			t.Errorf("expected the VM directory to be removed: %v", err)
+		}
+
+		// Unregister keeps the files
+		vm = findVM(ctx, t, c, "VM0")
+
+		if vmxPath, err = vm.VmxPath(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		task, err = vm.PowerOff(ctx)
+		wait(ctx, t, task, err)
+
+		// the wait for vmrest to stop listing the VM is bounded, a copy of the library keeps it listed
+		inventory := object.InventoryFile
+		data, err := os.ReadFile(inventory())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vmls := filepath.Join(t.TempDir(), "inventory.vmls")
+		if err = os.WriteFile(vmls, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// a library which does not list every VM reported by vmrest is not edited
+		other := filepath.Join(t.TempDir(), "inventory.vmls")
+		library := ".encoding = \"UTF-8\"\nvmlist1.config = \"/enoent/VM9/VM9.vmx\"\n"
+		if err = os.WriteFile(other, []byte(library), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		object.InventoryFile = func() string { return other }
+
+		if err = vm.Unregister(ctx); err == nil || !strings.Contains(err.Error(), "does not list") {
+			t.Errorf("expected an error editing a library which is not the vmrest one, got %v", err)
+		}
+
+		if b, _ := os.ReadFile(other); string(b) != library {
+			t.Error("expected the library to be left unchanged")
+		}
+
+		object.InventoryFile = func() string { return vmls }
+		object.UnregisterTimeout = 100 * time.Millisecond
+
+		err = vm.Unregister(ctx)
+
+		object.InventoryFile = inventory
+		object.UnregisterTimeout = time.Minute
+
+		if err == nil {
+			t.Error("expected a timeout error")
+		}
+
+		if err = vm.Unregister(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = find.NewFinder(c).VirtualMachine(ctx, "VM0"); err == nil {
+			t.Error("expected VM0 to be unregistered")
+		}
+
+		if _, err = os.Stat(vmxPath); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestVirtualMachineReconfigure(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM0")
+
+		// settings other than the CPUs and memory are applied through the config params when powered on
+		task, err := vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+			Annotation: "powered on",
+			ExtraConfig: []types.BaseOptionValue{
+				&types.OptionValue{Key: "guestinfo.foo", Value: "bar"},
+			},
+		})
+		wait(ctx, t, task, err)
+
+		cfg := loadVMX(ctx, t, vm)
+		if cfg.Get("annotation") != "powered on" || cfg.Get("guestinfo.foo") != "bar" {
+			t.Errorf("unexpected vmx: %s %s", cfg.Get("annotation"), cfg.Get("guestinfo.foo"))
+		}
+
+		if _, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{NumCPUs: 2}); err == nil {
+			t.Error("expected an error changing the CPUs of a powered on VM")
+		}
+
+		if _, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+			LatencySensitivity: &types.LatencySensitivity{Level: types.LatencySensitivitySensitivityLevelHigh},
+		}); !errors.Is(err, object.ErrNotSupported) {
+			t.Errorf("expected ErrNotSupported, got %v", err)
+		}
+
+		task, err = vm.PowerOff(ctx)
+		wait(ctx, t, task, err)
+
+		task, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+			NumCPUs:    2,
+			MemoryMB:   2048,
+			Annotation: "powered off",
+			ExtraConfig: []types.BaseOptionValue{
+				&types.OptionValue{Key: "guestinfo.foo", Value: ""},
+			},
+		})
+		wait(ctx, t, task, err)
+
+		cfg = loadVMX(ctx, t, vm)
+
+		for key, value := range map[string]string{
+			"numvcpus":   "2",
+			"memsize":    "2048",
+			"annotation": "powered off",
+		} {
+			if cfg.Get(key) != value {
+				t.Errorf("%s: expected %q, got %q", key, value, cfg.Get(key))
+			}
+		}
+
+		if cfg.Has("guestinfo.foo") {
+			t.Error("expected guestinfo.foo to be removed")
+		}
+	})
+}
+
+func TestVirtualMachineDeviceChange(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM0")
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		disks := func() []string {
+			files, _ := filepath.Glob(filepath.Join(filepath.Dir(vmxPath), "*.vmdk"))
+			return files
+		}
+
+		newDisk := func() *types.VirtualDisk {
+			devices, err := vm.Device(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			controller, err := devices.FindDiskController("scsi")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			disk := devices.CreateDisk(controller, "")
+			disk.CapacityInKB = 1024 * 1024
+
+			return disk
+		}
+
+		add := &types.VirtualDeviceConfigSpec{
+			Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+			FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+			Device:        newDisk(),
+		}
+
+		// the device changes require the virtual machine to be powered off
+		_, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: []types.BaseVirtualDeviceConfigSpec{add}})
+		if _, ok := err.(*object.InvalidPowerStateError); !ok {
+			t.Errorf("expected InvalidPowerStateError, got %v", err)
+		}
+
+		task, err := vm.PowerOff(ctx)
+		wait(ctx, t, task, err)
+
+		before := disks()
+		vmxData, err := os.ReadFile(vmxPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		unchanged := func() {
+			t.Helper()
+
+			if after := disks(); len(after) != len(before) {
+				t.Errorf("expected %v disk files, got %v", before, after)
+			}
+
+			if data, _ := os.ReadFile(vmxPath); string(data) != string(vmxData) {
+				t.Error("expected the vmx to be unchanged")
+			}
+		}
+
+		// an invalid change fails before any disk file is created
+		_, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+			NumCPUs: 4,
+			DeviceChange: []types.BaseVirtualDeviceConfigSpec{add, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationRemove,
+				Device:    &types.VirtualDisk{VirtualDevice: types.VirtualDevice{Key: 99999}},
+			}},
+		})
+		if err == nil {
+			t.Fatal("expected an error removing an unknown device")
+		}
+		unchanged()
+
+		// the disk created is removed when extending the other disk fails
+		devices, err := vm.Device(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		disk := devices.SelectByType((*types.VirtualDisk)(nil))[0].(*types.VirtualDisk)
+		name := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).FileName
+		disk.CapacityInBytes *= 2
+		disk.CapacityInKB *= 2
+
+		if err = os.Mkdir(name+".lck", 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		// the CPUs set through vmrest are restored too
+		_, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+			NumCPUs: 4,
+			DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{
+					Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+					FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+					Device:        newDisk(),
+				},
+				&types.VirtualDeviceConfigSpec{
+					Operation: types.VirtualDeviceConfigSpecOperationEdit,
+					Device:    disk,
+				},
+			},
+		})
+		if err == nil {
+			t.Fatal("expected an error extending a locked disk")
+		}
+		unchanged()
+
+		if err = os.Remove(name + ".lck"); err != nil {
+			t.Fatal(err)
+		}
+
+		// the disk file is deleted once the vmx no longer references it
+		add.Device = newDisk()
+
+		task, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{NumCPUs: 4, DeviceChange: []types.BaseVirtualDeviceConfigSpec{add}})
+		wait(ctx, t, task, err)
+
+		if after := disks(); len(after) != len(before)+1 {
+			t.Fatalf("expected a new disk file, got %v", after)
+		}
+
+		if cfg := loadVMX(ctx, t, vm); cfg.Get("numvcpus") != "4" || !cfg.Has("scsi0:1.fileName") {
+			t.Errorf("unexpected vmx: numvcpus=%s scsi0:1.fileName=%s", cfg.Get("numvcpus"), cfg.Get("scsi0:1.fileName"))
+		}
+
+		devices, err = vm.Device(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		remove := &types.VirtualDeviceConfigSpec{
+			Operation:     types.VirtualDeviceConfigSpecOperationRemove,
+			FileOperation: types.VirtualDeviceConfigSpecFileOperationDestroy,
+			Device:        devices.SelectByType((*types.VirtualDisk)(nil))[1],
+		}
+
+		// a disk with child disks is not removed
+		parent := remove.Device.GetVirtualDevice().Backing.(*types.VirtualDiskFlatVer2BackingInfo).FileName
+		child := filepath.Join(filepath.Dir(parent), "child.vmdk")
+
+		if err = vmdk.CreateChild(child, filepath.Base(parent)); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: []types.BaseVirtualDeviceConfigSpec{remove}}); !errors.Is(err, vmdk.ErrHasChildren) {
+			t.Errorf("expected ErrHasChildren, got %v", err)
+		}
+
+		if !loadVMX(ctx, t, vm).Has("scsi0:1.fileName") {
+			t.Error("expected scsi0:1 to be kept")
+		}
+
+		if err = vmdk.Delete(child); err != nil {
+			t.Fatal(err)
+		}
+
+		task, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: []types.BaseVirtualDeviceConfigSpec{remove}})
+		wait(ctx, t, task, err)
+
+		if after := disks(); len(after) != len(before) {
+			t.Errorf("expected the disk file to be deleted, got %v", after)
+		}
+
+		if loadVMX(ctx, t, vm).Has("scsi0:1.fileName") {
+			t.Error("expected scsi0:1 to be removed")
+		}
+
+		// the static MAC addresses must be in the VMware range
+		for _, mac := range []string{"00:0c:29:00:00:01", "00:50:56:40:00:01", "enoent"} {
+			_, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+				&types.VirtualDeviceConfigSpec{
+					Operation: types.VirtualDeviceConfigSpecOperationAdd,
+					Device: &types.VirtualVmxnet3{VirtualVmxnet: types.VirtualVmxnet{VirtualEthernetCard: types.VirtualEthernetCard{
+						AddressType: string(types.VirtualEthernetCardMacTypeManual),
+						MacAddress:  mac,
+					}}},
+				},
+			}})
+			if err == nil {
+				t.Errorf("%s: expected an error", mac)
+			}
+		}
+
+		task, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+			&types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationAdd,
+				Device: &types.VirtualVmxnet3{VirtualVmxnet: types.VirtualVmxnet{VirtualEthernetCard: types.VirtualEthernetCard{
+					AddressType: string(types.VirtualEthernetCardMacTypeManual),
+					MacAddress:  "00:50:56:3f:00:01",
+				}}},
+			},
+		}})
+		wait(ctx, t, task, err)
+
+		if cfg := loadVMX(ctx, t, vm); cfg.Get("ethernet1.address") != "00:50:56:3F:00:01" {
+			t.Errorf("unexpected vmx: ethernet1.address=%s", cfg.Get("ethernet1.address"))
+		}
+	})
+}
+
+func TestVirtualMachineDevice(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM0")
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, deviceType := range []string{"disk", "scsi-hardDisk", "ata-hardDisk"} {
+			cfg := loadVMX(ctx, t, vm)
+			cfg.Set("scsi0:0.deviceType", deviceType)
+
+			if err = cfg.Save(vmxPath); err != nil {
+				t.Fatal(err)
+			}
+
+			devices, err := vm.Device(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if disks := devices.SelectByType((*types.VirtualDisk)(nil)); len(disks) != 1 {
+				t.Errorf("%s: expected 1 disk, got %d", deviceType, len(disks))
+			}
+		}
+	})
+}
+
+func TestVirtualMachineLinkedClone(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM1")
+
+		task, err := vm.PowerOff(ctx)
+		wait(ctx, t, task, err)
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// a second disk with the base name of the first one
+		other := filepath.Join(filepath.Dir(vmxPath), "other", "VM1.vmdk")
+
+		if err = os.Mkdir(filepath.Dir(other), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = vmdk.Create(other, vmdk.MonolithicSparse, 1<<20, "lsilogic"); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := loadVMX(ctx, t, vm)
+		cfg.Set("scsi0:1.present", "TRUE")
+		cfg.Set("scsi0:1.fileName", other)
+		cfg.Set("scsi0:2.present", "TRUE")
+		cfg.Set("scsi0:2.fileName", "enoent.vmdk")
+
+		if err = cfg.Save(vmxPath); err != nil {
+			t.Fatal(err)
+		}
+
+		link := types.VirtualMachineCloneSpec{
+			Location: types.VirtualMachineRelocateSpec{
+				DiskMoveType: string(types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking),
+			},
+		}
+
+		// the child disks are deleted when the snapshot fails
+		if _, err = vm.Clone(ctx, nil, "VM2", link); err == nil {
+			t.Fatal("expected an error linking to a missing disk")
+		}
+
+		for _, dir := range []string{filepath.Dir(vmxPath), filepath.Dir(other)} {
+			if deltas, _ := filepath.Glob(filepath.Join(dir, "*-000001.vmdk")); len(deltas) != 0 {
+				t.Errorf("unexpected child disks: %v", deltas)
+			}
+		}
+
+		if cfg = loadVMX(ctx, t, vm); cfg.Get("scsi0:0.fileName") != "VM1.vmdk" || cfg.Get("scsi0:1.fileName") != other {
+			t.Errorf("unexpected vmx disks: %s %s", cfg.Get("scsi0:0.fileName"), cfg.Get("scsi0:1.fileName"))
+		}
+
+		cfg.RemovePrefix("scsi0:2.")
+
+		if err = cfg.Save(vmxPath); err != nil {
+			t.Fatal(err)
+		}
+
+		// the disks of the linked clone are named after their node
+		task, err = vm.Clone(ctx, nil, "VM2", link)
+		wait(ctx, t, task, err)
+
+		devices, err := findVM(ctx, t, c, "VM2").Device(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := map[string]bool{}
+
+		for _, disk := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+			backing := disk.GetVirtualDevice().Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+			names[filepath.Base(backing.FileName)] = backing.Parent != nil
+		}
+
+		if !names["VM1-scsi0-0-cl1.vmdk"] || !names["VM1-scsi0-1-cl1.vmdk"] {
+			t.Errorf("unexpected clone disks: %v", names)
+		}
+	})
+}
@@ -0,0 +1,830 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// diskOperation is a change of a disk file, run when the vmx is saved
+type diskOperation struct {
+	name   string
+	create bool
+	run    func() error
+}
+
+// vmxDeviceConfig applies the device changes of a config spec to a vmx file
+type vmxDeviceConfig struct {
+	path    string
+	cfg     *vmx.File
+	devices VirtualDeviceList
+
+	prepare []diskOperation // disk files created or extended before the vmx is saved
+	cleanup []diskOperation // disk files deleted once the vmx no longer references them
+	created []string        // disk files created by save
+}
+
+func newVMXDeviceConfig(vmxPath string, cfg *vmx.File) *vmxDeviceConfig {
+	return &vmxDeviceConfig{
+		path:    vmxPath,
+		cfg:     cfg,
+		devices: readVMXDevices(vmxPath, cfg),
+	}
+}
+
+// apply writes the device changes to the vmx, the disk file operations are validated and run by save
+func (w *vmxDeviceConfig) apply(changes []types.BaseVirtualDeviceConfigSpec) error {
+	for _, change := range changes {
+		spec := change.GetVirtualDeviceConfigSpec()
+
+		var err error
+
+		switch spec.Operation {
+		case types.VirtualDeviceConfigSpecOperationAdd:
+			err = w.add(spec.Device, spec.FileOperation)
+		case types.VirtualDeviceConfigSpecOperationEdit:
+			err = w.edit(spec.Device)
+		case types.VirtualDeviceConfigSpecOperationRemove:
+			err = w.remove(spec.Device, spec.FileOperation)
+		default:
+			err = fmt.Errorf("%w: %s device operation", ErrNotSupported, spec.Operation)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// save creates and extends the disk files, saves the vmx then deletes the destroyed disk files.
+// The disk files created are removed when a disk operation or the vmx save fails.
+func (w *vmxDeviceConfig) save() error {
+	for _, op := range w.prepare {
+		if err := op.run(); err != nil {
+			w.rollback()
+			return err
+		}
+
+		if op.create {
+			w.created = append(w.created, op.name)
+		}
+	}
+
+	if err := w.cfg.Save(w.path); err != nil {
+		w.rollback()
+		return err
+	}
+
+	var errs []string
+
+	for _, op := range w.cleanup {
+		if err := op.run(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) != 0 {
+		return fmt.Errorf("%s saved, but: %s", w.path, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// rollback deletes the disk files created by save
+func (w *vmxDeviceConfig) rollback() {
+	for _, name := range w.created {
+		_ = vmdk.Delete(name)
+	}
+
+	w.created = nil
+}
+
+// creates returns true if the disk file is created by save
+func (w *vmxDeviceConfig) creates(name string) bool {
+	for _, op := range w.prepare {
+		if op.create && op.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *vmxDeviceConfig) present(prefix string) bool {
+	b := w.cfg.Bool(prefix + "present")
+	return b != nil && *b
+}
+
+// relative returns the name of a file referenced by the vmx, the files of the VM directory are referenced by their base name
+func (w *vmxDeviceConfig) relative(name string) string {
+	if filepath.IsAbs(name) && filepath.Dir(name) == filepath.Dir(w.path) {
+		return filepath.Base(name)
+	}
+
+	return name
+}
+
+// bus returns the vmx bus of the disk controller, "scsi0" is the SCSI controller 0
+func (w *vmxDeviceConfig) bus(key int32) (vmxBus, int32, error) {
+	c, ok := w.devices.FindByKey(key).(types.BaseVirtualController)
+	if !ok {
+		return vmxBus{}, 0, fmt.Errorf("controller %d not found", key)
+	}
+
+	var name string
+
+	switch c.(type) {
+	case *types.VirtualIDEController:
+		name = "ide"
+	case types.BaseVirtualSCSIController:
+		name = "scsi"
+	case types.BaseVirtualSATAController:
+		name = "sata"
+	case *types.VirtualNVMEController:
+		name = "nvme"
+	default:
+		return vmxBus{}, 0, fmt.Errorf("%s is not a disk controller", w.devices.Name(c.(types.BaseVirtualDevice)))
+	}
+
+	for _, bus := range vmxBuses {
+		if bus.name == name {
+			return bus, c.GetVirtualController().BusNumber, nil
+		}
+	}
+
+	return vmxBus{}, 0, fmt.Errorf("unknown %s bus", name)
+}
+
+// node returns the vmx node of a device attached to a disk controller, "scsi0:1" is the unit 1 of the SCSI controller 0
+func (w *vmxDeviceConfig) node(d *types.VirtualDevice) (string, error) {
+	bus, n, err := w.bus(d.ControllerKey)
+	if err != nil {
+		return "", err
+	}
+
+	if d.UnitNumber == nil || *d.UnitNumber < 0 || *d.UnitNumber >= bus.units || bus.name == "scsi" && *d.UnitNumber == 7 {
+		return "", fmt.Errorf("invalid %s%d unit number", bus.name, n)
+	}
+
+	node := fmt.Sprintf("%s%d:%d", bus.name, n, *d.UnitNumber)
+
+	if w.present(node + ".") {
+		return "", fmt.Errorf("%s is already in use", node)
+	}
+
+	return node, nil
+}
+
+func (w *vmxDeviceConfig) connectable(prefix string, d *types.VirtualDevice) {
+	if d.Connectable != nil {
+		w.cfg.SetBool(prefix+"startConnected", d.Connectable.StartConnected)
+	}
+}
+
+func (w *vmxDeviceConfig) add(device types.BaseVirtualDevice, fop types.VirtualDeviceConfigSpecFileOperation) error {
+	var err error
+
+	switch d := device.(type) {
+	case *types.VirtualIDEController:
+		err = fmt.Errorf("%w: the IDE controllers are always present", ErrNotSupported)
+	case types.BaseVirtualSCSIController:
+		err = w.addController(device, "scsi", scsiVirtualDev(device))
+	case types.BaseVirtualSATAController:
+		err = w.addController(device, "sata", "")
+	case *types.VirtualNVMEController:
+		err = w.addController(device, "nvme", "")
+	case *types.VirtualDisk:
+		err = w.addDisk(d, fop)
+	case *types.VirtualCdrom:
+		err = w.addCdrom(d)
+	case types.BaseVirtualEthernetCard:
+		err = w.addEthernetCard(d)
+	case *types.VirtualSerialPort:
+		err = w.addSerialPort(d)
+	default:
+		err = fmt.Errorf("%w: adding %s devices", ErrNotSupported, w.devices.TypeName(device))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w.devices = append(w.devices, device)
+
+	return nil
+}
+
+// existing returns the device of the vmx with the key of the given device and its vmx prefix, "scsi0:1." or "ethernet0."
+func (w *vmxDeviceConfig) existing(device types.BaseVirtualDevice) (types.BaseVirtualDevice, string, error) {
+	key := device.GetVirtualDevice().Key
+
+	d := w.devices.FindByKey(key)
+	if d == nil {
+		return nil, "", fmt.Errorf("device %d not found", key)
+	}
+
+	if reflect.TypeOf(d) != reflect.TypeOf(device) {
+		return nil, "", fmt.Errorf("device %d is a %s", key, w.devices.TypeName(d))
+	}
+
+	unit := func(d *types.VirtualDevice) int32 {
+		if d.UnitNumber == nil {
+			return -1
+		}
+		return *d.UnitNumber
+	}
+
+	v := d.GetVirtualDevice()
+
+	switch c := d.(type) {
+	case *types.VirtualDisk, *types.VirtualCdrom:
+		bus, n, err := w.bus(v.ControllerKey)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return d, fmt.Sprintf("%s%d:%d.", bus.name, n, unit(v)), nil
+	case types.BaseVirtualEthernetCard:
+		return d, fmt.Sprintf("ethernet%d.", unit(v)-7), nil
+	case *types.VirtualSerialPort:
+		return d, fmt.Sprintf("serial%d.", unit(v)), nil
+	case *types.VirtualFloppy:
+		return d, fmt.Sprintf("floppy%d.", unit(v)), nil
+	case types.BaseVirtualSCSIController:
+		return d, fmt.Sprintf("scsi%d.", c.GetVirtualSCSIController().BusNumber), nil
+	case types.BaseVirtualSATAController:
+		return d, fmt.Sprintf("sata%d.", c.GetVirtualSATAController().BusNumber), nil
+	case *types.VirtualNVMEController:
+		return d, fmt.Sprintf("nvme%d.", c.BusNumber), nil
+	case types.BaseVirtualSoundCard:
+		return d, "sound.", nil
+	case *types.VirtualUSBController:
+		return d, "usb.", nil
+	case *types.VirtualUSBXHCIController:
+		return d, "usb_xhci.", nil
+	default:
+		return nil, "", fmt.Errorf("%w: %s devices", ErrNotSupported, w.devices.TypeName(d))
+	}
+}
+
+// remove removes a device from the vmx, the disk files are deleted with the destroy file operation
+func (w *vmxDeviceConfig) remove(device types.BaseVirtualDevice, fop types.VirtualDeviceConfigSpecFileOperation) error {
+	d, prefix, err := w.existing(device)
+	if err != nil {
+		return err
+	}
+
+	key := d.GetVirtualDevice().Key
+	name := w.devices.Name(d)
+
+	switch d := d.(type) {
+	case types.BaseVirtualController:
+		attached := w.devices.Select(func(device types.BaseVirtualDevice) bool {
+			return device.GetVirtualDevice().ControllerKey == key
+		})
+
+		if len(attached) != 0 {
+			return fmt.Errorf("%s has %d devices attached", name, len(attached))
+		}
+	case *types.VirtualDisk:
+		if fop == types.VirtualDeviceConfigSpecFileOperationDestroy {
+			backing, ok := d.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+			if !ok {
+				return fmt.Errorf("%w: %T disk backing", ErrNotSupported, d.Backing)
+			}
+
+			if w.creates(backing.FileName) {
+				return fmt.Errorf("%s is created by this change", backing.FileName)
+			}
+
+			if _, err = vmdk.Read(backing.FileName); err != nil {
+				return err
+			}
+
+			// the delete would fail once the vmx is saved
+			if err = vmdk.NoChildren(backing.FileName); err != nil {
+				return err
+			}
+
+			name := backing.FileName
+
+			w.cleanup = append(w.cleanup, diskOperation{name: name, run: func() error {
+				return vmdk.Delete(name)
+			}})
+		}
+	}
+
+	w.cfg.RemovePrefix(prefix)
+
+	if _, ok := d.(*types.VirtualUSBController); ok {
+		w.cfg.RemovePrefix("ehci.")
+	}
+
+	w.devices = w.devices.Select(func(device types.BaseVirtualDevice) bool {
+		return device.GetVirtualDevice().Key != key
+	})
+
+	return nil
+}
+
+// edit applies the settings of a device to the vmx, its placement is left unchanged
+func (w *vmxDeviceConfig) edit(device types.BaseVirtualDevice) error {
+	d, prefix, err := w.existing(device)
+	if err != nil {
+		return err
+	}
+
+	switch dev := device.(type) {
+	case types.BaseVirtualSCSIController:
+		w.cfg.Set(prefix+"virtualDev", scsiVirtualDev(device))
+	case *types.VirtualDisk:
+		err = w.editDisk(prefix, d.(*types.VirtualDisk), dev)
+	case *types.VirtualCdrom:
+		err = w.cdrom(prefix, dev)
+	case types.BaseVirtualEthernetCard:
+		err = w.ethernetCard(prefix, dev)
+	case *types.VirtualSerialPort:
+		err = w.serialPort(prefix, dev)
+	default:
+		err = fmt.Errorf("%w: editing %s devices", ErrNotSupported, w.devices.TypeName(device))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// the device keeps the placement of the vmx device
+	v := device.GetVirtualDevice()
+	v.ControllerKey = d.GetVirtualDevice().ControllerKey
+	v.UnitNumber = d.GetVirtualDevice().UnitNumber
+
+	for i := range w.devices {
+		if w.devices[i] == d {
+			w.devices[i] = device
+		}
+	}
+
+	return nil
+}
+
+// scsiVirtualDev returns the vmx virtualDev of a SCSI controller
+func scsiVirtualDev(device types.BaseVirtualDevice) string {
+	switch device.(type) {
+	case *types.VirtualLsiLogicSASController:
+		return "lsisas1068"
+	case *types.ParaVirtualSCSIController:
+		return "pvscsi"
+	case *types.VirtualBusLogicController:
+		return "buslogic"
+	default:
+		return "lsilogic"
+	}
+}
+
+func (w *vmxDeviceConfig) addController(device types.BaseVirtualDevice, name, virtualDev string) error {
+	c := device.(types.BaseVirtualController).GetVirtualController()
+
+	var buses int32
+
+	for _, bus := range vmxBuses {
+		if bus.name == name {
+			buses = bus.buses
+		}
+	}
+
+	if c.BusNumber < 0 {
+		for n := int32(0); n < buses; n++ {
+			if !w.present(fmt.Sprintf("%s%d.", name, n)) {
+				c.BusNumber = n
+				break
+			}
+		}
+	}
+
+	prefix := fmt.Sprintf("%s%d.", name, c.BusNumber)
+
+	if c.BusNumber < 0 || c.BusNumber >= buses {
+		return fmt.Errorf("no available %s bus", strings.ToUpper(name))
+	}
+
+	if w.present(prefix) {
+		return fmt.Errorf("%s%d is already present", name, c.BusNumber)
+	}
+
+	w.cfg.Set(prefix+"present", "TRUE")
+
+	if virtualDev != "" {
+		w.cfg.Set(prefix+"virtualDev", virtualDev)
+	}
+
+	return nil
+}
+
+// diskName returns the name of a new disk of the VM, named after the vmx file
+func (w *vmxDeviceConfig) diskName() string {
+	base := strings.TrimSuffix(filepath.Base(w.path), filepath.Ext(w.path))
+
+	for i := 0; ; i++ {
+		name := base + ".vmdk"
+		if i != 0 {
+			name = fmt.Sprintf("%s-%d.vmdk", base, i)
+		}
+
+		used := len(w.devices.SelectByBackingInfo(&types.VirtualDiskFlatVer2BackingInfo{
+			VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: resolve(w.path, name)},
+		})) != 0
+
+		if _, err := os.Stat(resolve(w.path, name)); os.IsNotExist(err) && !used {
+			return name
+		}
+	}
+}
+
+// adapterType returns the disk database adapter type of the disks attached to the controller.
+// The disk database only knows vmdk.AdapterTypes, as Workstation does the LSI Logic SAS and
+// VMware Paravirtual SCSI disks are recorded as lsilogic, and so are the NVMe disks.
+func (w *vmxDeviceConfig) adapterType(key int32) string {
+	switch w.devices.FindByKey(key).(type) {
+	case *types.VirtualIDEController, types.BaseVirtualSATAController:
+		return "ide"
+	case *types.VirtualBusLogicController:
+		return "buslogic"
+	case *types.VirtualLsiLogicController, *types.VirtualLsiLogicSASController, *types.ParaVirtualSCSIController:
+		return "lsilogic"
+	default:
+		return "lsilogic"
+	}
+}
+
+func (w *vmxDeviceConfig) addDisk(disk *types.VirtualDisk, fop types.VirtualDeviceConfigSpecFileOperation) error {
+	backing, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	if !ok {
+		return fmt.Errorf("%w: %T disk backing", ErrNotSupported, disk.Backing)
+	}
+
+	node, err := w.node(&disk.VirtualDevice)
+	if err != nil {
+		return err
+	}
+
+	name := backing.FileName
+	if name == "" || strings.HasSuffix(name, "/") {
+		name = w.diskName()
+	}
+
+	name = resolve(w.path, name)
+
+	create := backing.Parent != nil || fop == types.VirtualDeviceConfigSpecFileOperationCreate
+
+	if create {
+		if _, err = os.Stat(name); err == nil || w.creates(name) {
+			return fmt.Errorf("%s already exists", name)
+		}
+	}
+
+	switch {
+	case backing.Parent != nil:
+		parent := w.relative(resolve(w.path, backing.Parent.FileName))
+
+		if _, err = vmdk.Read(resolve(name, parent)); err != nil {
+			return err
+		}
+
+		w.prepare = append(w.prepare, diskOperation{name: name, create: true, run: func() error {
+			return vmdk.CreateChild(name, parent)
+		}})
+	case create:
+		capacity := diskCapacity(disk)
+		if capacity <= 0 {
+			return fmt.Errorf("%s: invalid capacity %d", name, capacity)
+		}
+
+		adapterType := w.adapterType(disk.ControllerKey)
+
+		w.prepare = append(w.prepare, diskOperation{name: name, create: true, run: func() error {
+			return vmdk.Create(name, vmdk.MonolithicSparse, capacity, adapterType)
+		}})
+	default:
+		if _, err = vmdk.Read(name); err != nil && !w.creates(name) {
+			return err
+		}
+	}
+
+	prefix := node + "."
+
+	w.cfg.Set(prefix+"present", "TRUE")
+	w.cfg.Set(prefix+"fileName", w.relative(name))
+	w.diskMode(prefix, backing)
+
+	backing.FileName = name
+
+	return nil
+}
+
+// diskCapacity returns the capacity of a disk in bytes
+func diskCapacity(disk *types.VirtualDisk) int64 {
+	if disk.CapacityInBytes != 0 {
+		return disk.CapacityInBytes
+	}
+
+	return disk.CapacityInKB * 1024
+}
+
+// editCapacity returns the capacity of an edited disk in bytes, the capacity may be changed in bytes or in KB
+func editCapacity(current, disk *types.VirtualDisk) int64 {
+	if disk.CapacityInBytes == current.CapacityInBytes && disk.CapacityInKB != current.CapacityInKB {
+		return disk.CapacityInKB * 1024
+	}
+
+	return diskCapacity(disk)
+}
+
+// diskMode writes the disk mode, persistent is the default mode
+func (w *vmxDeviceConfig) diskMode(prefix string, backing *types.VirtualDiskFlatVer2BackingInfo) {
+	if backing.DiskMode == "" || backing.DiskMode == string(types.VirtualDiskModePersistent) {
+		w.cfg.Remove(prefix + "mode")
+	} else {
+		w.cfg.Set(prefix+"mode", backing.DiskMode)
+	}
+}
+
+// editDisk replaces the file of a disk with an existing disk file, or grows its capacity, and changes its mode
+func (w *vmxDeviceConfig) editDisk(prefix string, current, disk *types.VirtualDisk) error {
+	backing, ok := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo)
+	if !ok {
+		return fmt.Errorf("%w: %T disk backing", ErrNotSupported, disk.Backing)
+	}
+
+	var currentName string
+	if b, ok := current.Backing.(*types.VirtualDiskFlatVer2BackingInfo); ok {
+		currentName = b.FileName
+	}
+
+	name := resolve(w.path, backing.FileName)
+
+	if name != currentName {
+		if _, err := vmdk.Read(name); err != nil {
+			return err
+		}
+
+		w.cfg.Set(prefix+"fileName", w.relative(name))
+	} else if capacity := editCapacity(current, disk); capacity != 0 && capacity != diskCapacity(current) {
+		if capacity < diskCapacity(current) {
+			return fmt.Errorf("%s: cannot shrink from %d to %d bytes", name, diskCapacity(current), capacity)
+		}
+
+		w.prepare = append(w.prepare, diskOperation{name: name, run: func() error {
+			return vmdk.Extend(name, capacity)
+		}})
+	}
+
+	w.diskMode(prefix, backing)
+
+	backing.FileName = name
+
+	return nil
+}
+
+func (w *vmxDeviceConfig) addCdrom(cdrom *types.VirtualCdrom) error {
+	node, err := w.node(&cdrom.VirtualDevice)
+	if err != nil {
+		return err
+	}
+
+	prefix := node + "."
+
+	w.cfg.Set(prefix+"present", "TRUE")
+
+	return w.cdrom(prefix, cdrom)
+}
+
+// cdrom writes the backing of a CD-ROM, an ISO image or a host device
+func (w *vmxDeviceConfig) cdrom(prefix string, cdrom *types.VirtualCdrom) error {
+	switch backing := cdrom.Backing.(type) {
+	case *types.VirtualCdromIsoBackingInfo:
+		w.cfg.Set(prefix+"deviceType", "cdrom-image")
+		w.cfg.Set(prefix+"fileName", w.relative(backing.FileName))
+		w.cfg.Remove(prefix + "autodetect")
+	case types.BaseVirtualDeviceDeviceBackingInfo:
+		device := backing.GetVirtualDeviceDeviceBackingInfo()
+		name := device.DeviceName
+		auto := device.UseAutoDetect != nil && *device.UseAutoDetect
+
+		if name == "" || name == "auto detect" {
+			name, auto = "auto detect", true
+		}
+
+		w.cfg.Set(prefix+"deviceType", "cdrom-raw")
+		w.cfg.Set(prefix+"fileName", name)
+		w.cfg.SetBool(prefix+"autodetect", auto)
+	default:
+		return fmt.Errorf("%w: %T CD-ROM backing", ErrNotSupported, backing)
+	}
+
+	w.connectable(prefix, &cdrom.VirtualDevice)
+
+	return nil
+}
+
+// connectionType returns the vmx connection type and custom vnet of a vmnet, the inverse of networkName
+func connectionType(vmnet string) (string, string) {
+	switch vmnet {
+	case "", "vmnet8":
+		return "nat", ""
+	case "vmnet0":
+		return "bridged", ""
+	case "vmnet1":
+		return "hostonly", ""
+	default:
+		return "custom", vmnet
+	}
+}
+
+func (w *vmxDeviceConfig) addEthernetCard(device types.BaseVirtualEthernetCard) error {
+	card := device.GetVirtualEthernetCard()
+	index := -1
+
+	if card.UnitNumber != nil && *card.UnitNumber >= 7 {
+		index = int(*card.UnitNumber) - 7
+	} else {
+		for i := 0; i < maxEthernet; i++ {
+			if !w.present(fmt.Sprintf("ethernet%d.", i)) {
+				index = i
+				break
+			}
+		}
+	}
+
+	if index < 0 || index >= maxEthernet {
+		return fmt.Errorf("no available network adapter slot")
+	}
+
+	prefix := fmt.Sprintf("ethernet%d.", index)
+
+	if w.present(prefix) {
+		return fmt.Errorf("ethernet%d is already present", index)
+	}
+
+	w.cfg.Set(prefix+"present", "TRUE")
+
+	if err := w.ethernetCard(prefix, device); err != nil {
+		w.cfg.RemovePrefix(prefix)
+		return err
+	}
+
+	card.UnitNumber = types.NewInt32(int32(7 + index))
+
+	return nil
+}
+
+// ethernetCard writes the network, the device type and the address type of a network adapter
+func (w *vmxDeviceConfig) ethernetCard(prefix string, device types.BaseVirtualEthernetCard) error {
+	card := device.GetVirtualEthernetCard()
+
+	if card.AddressType == string(types.VirtualEthernetCardMacTypeManual) && card.MacAddress != "" {
+		if err := validStaticMAC(card.MacAddress); err != nil {
+			return err
+		}
+	}
+
+	var vmnet string
+
+	switch backing := card.Backing.(type) {
+	case nil:
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		vmnet = backing.DeviceName
+	default:
+		return fmt.Errorf("%w: %T network adapter backing", ErrNotSupported, backing)
+	}
+
+	connection, vnet := connectionType(vmnet)
+
+	w.cfg.Set(prefix+"connectionType", connection)
+
+	if vnet != "" {
+		w.cfg.Set(prefix+"vnet", vnet)
+	} else {
+		w.cfg.Remove(prefix + "vnet")
+	}
+
+	switch device.(type) {
+	case *types.VirtualPCNet32:
+		w.cfg.Remove(prefix + "virtualDev")
+	case *types.VirtualE1000:
+		w.cfg.Set(prefix+"virtualDev", "e1000")
+	case *types.VirtualE1000e:
+		w.cfg.Set(prefix+"virtualDev", "e1000e")
+	case *types.VirtualVmxnet2:
+		w.cfg.Set(prefix+"virtualDev", "vmxnet")
+	case *types.VirtualVmxnet3:
+		w.cfg.Set(prefix+"virtualDev", "vmxnet3")
+	}
+
+	if card.AddressType == string(types.VirtualEthernetCardMacTypeManual) && card.MacAddress != "" {
+		w.cfg.Set(prefix+"addressType", "static")
+		w.cfg.Set(prefix+"address", strings.ToUpper(card.MacAddress))
+	} else {
+		w.cfg.Set(prefix+"addressType", "generated")
+		w.cfg.Remove(prefix + "address")
+	}
+
+	w.connectable(prefix, &card.VirtualDevice)
+
+	return nil
+}
+
+func (w *vmxDeviceConfig) addSerialPort(port *types.VirtualSerialPort) error {
+	index := -1
+
+	if port.UnitNumber != nil && *port.UnitNumber >= 0 && *port.UnitNumber < maxSerial && !w.present(fmt.Sprintf("serial%d.", *port.UnitNumber)) {
+		index = int(*port.UnitNumber)
+	} else {
+		for i := 0; i < maxSerial; i++ {
+			if !w.present(fmt.Sprintf("serial%d.", i)) {
+				index = i
+				break
+			}
+		}
+	}
+
+	if index < 0 {
+		return fmt.Errorf("no available serial port slot")
+	}
+
+	prefix := fmt.Sprintf("serial%d.", index)
+
+	w.cfg.Set(prefix+"present", "TRUE")
+
+	if err := w.serialPort(prefix, port); err != nil {
+		w.cfg.RemovePrefix(prefix)
+		return err
+	}
+
+	port.ControllerKey = sioControllerKey
+	port.UnitNumber = types.NewInt32(int32(index))
+
+	return nil
+}
+
+// serialPort writes the backing of a serial port: an output file, a host device or a named pipe
+func (w *vmxDeviceConfig) serialPort(prefix string, port *types.VirtualSerialPort) error {
+	w.cfg.Remove(prefix + "autodetect")
+	w.cfg.Remove(prefix + "pipe.endPoint")
+
+	switch backing := port.Backing.(type) {
+	case *types.VirtualSerialPortFileBackingInfo:
+		w.cfg.Set(prefix+"fileType", "file")
+		w.cfg.Set(prefix+"fileName", w.relative(backing.FileName))
+	case *types.VirtualSerialPortDeviceBackingInfo:
+		name := backing.DeviceName
+
+		w.cfg.Set(prefix+"fileType", "device")
+
+		if name == "" || name == "auto detect" || backing.UseAutoDetect != nil && *backing.UseAutoDetect {
+			w.cfg.Set(prefix+"fileName", "auto detect")
+			w.cfg.SetBool(prefix+"autodetect", true)
+		} else {
+			w.cfg.Set(prefix+"fileName", name)
+		}
+	case *types.VirtualSerialPortPipeBackingInfo:
+		endpoint := "server"
+		if backing.Endpoint == "client" {
+			endpoint = "client"
+		}
+
+		w.cfg.Set(prefix+"fileType", "pipe")
+		w.cfg.Set(prefix+"fileName", backing.PipeName)
+		w.cfg.Set(prefix+"pipe.endPoint", endpoint)
+	default:
+		return fmt.Errorf("%w: %T serial port backing", ErrNotSupported, backing)
+	}
+
+	w.cfg.SetBool(prefix+"yieldOnMsrRead", port.YieldOnPoll)
+	w.connectable(prefix, &port.VirtualDevice)
+
+	return nil
+}
@@ -0,0 +1,459 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/vmware/govmomi/units"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Device keys, as assigned by vSphere. The key of a device attached to a disk controller
+// is derived from the controller bus and the unit number.
+const (
+	pciControllerKey  = 100
+	ideControllerKey  = 200
+	sioControllerKey  = 400
+	scsiControllerKey = 1000
+	scsiDeviceKey     = 2000
+	ideDeviceKey      = 3000
+	ethernetDeviceKey = 4000
+	soundDeviceKey    = 5000
+	usbControllerKey  = 7000
+	floppyDeviceKey   = 8000
+	serialDeviceKey   = 9000
+	xhciControllerKey = 14000
+	sataControllerKey = 15000
+	sataDeviceKey     = 16000
+	nvmeControllerKey = 31000
+	nvmeDeviceKey     = 32000
+)
+
+const (
+	maxEthernet = 10
+	maxSerial   = 4
+	maxFloppy   = 2
+)
+
+// vmxBus describes the vmx nodes of a disk controller type, "scsi0:1" is the unit 1 of the bus 0
+type vmxBus struct {
+	name          string
+	buses         int32
+	units         int32
+	controllerKey int32
+	deviceKey     int32
+}
+
+var vmxBuses = []vmxBus{
+	{"ide", 2, 2, ideControllerKey, ideDeviceKey},
+	{"scsi", 4, 16, scsiControllerKey, scsiDeviceKey},
+	{"sata", 4, 30, sataControllerKey, sataDeviceKey},
+	{"nvme", 4, 15, nvmeControllerKey, nvmeDeviceKey},
+}
+
+// vmxDevices reads the virtual devices defined in a vmx file
+type vmxDevices struct {
+	path    string
+	cfg     *vmx.File
+	devices VirtualDeviceList
+	count   map[string]int
+}
+
+// readVMXDevices returns the virtual devices defined in the vmx, the file names are resolved relative to vmxPath
+func readVMXDevices(vmxPath string, cfg *vmx.File) VirtualDeviceList {
+	r := &vmxDevices{path: vmxPath, cfg: cfg, count: map[string]int{}}
+
+	r.add(&types.VirtualPCIController{}, pciControllerKey, 0, -1, "PCI controller 0", "PCI controller 0")
+	r.add(&types.VirtualSIOController{}, sioControllerKey, 0, -1, "SIO controller 0", "SIO controller 0")
+
+	for _, bus := range vmxBuses {
+		r.readBus(bus)
+	}
+
+	for i := 0; i < maxEthernet; i++ {
+		r.readEthernet(i)
+	}
+
+	r.readUSB()
+	r.readSound()
+
+	for i := 0; i < maxSerial; i++ {
+		r.readSerial(i)
+	}
+
+	for i := 0; i < maxFloppy; i++ {
+		r.readFloppy(i)
+	}
+
+	// controllers list the keys of their devices
+	for _, device := range r.devices {
+		d := device.GetVirtualDevice()
+		if c, ok := r.devices.FindByKey(d.ControllerKey).(types.BaseVirtualController); ok {
+			controller := c.GetVirtualController()
+			controller.Device = append(controller.Device, d.Key)
+		}
+	}
+
+	return r.devices
+}
+
+// flag returns the value of a boolean key, or the given default value if it is not set
+func (r *vmxDevices) flag(key string, value bool) bool {
+	if b := r.cfg.Bool(key); b != nil {
+		return *b
+	}
+
+	return value
+}
+
+func (r *vmxDevices) present(prefix string) bool {
+	return r.flag(prefix+"present", false)
+}
+
+// label returns the next label of a kind of device, "Hard disk 1", "Hard disk 2"...
+func (r *vmxDevices) label(kind string) string {
+	r.count[kind]++
+	return fmt.Sprintf("%s %d", kind, r.count[kind])
+}
+
+func (r *vmxDevices) connectable(prefix string) *types.VirtualDeviceConnectInfo {
+	connected := r.flag(prefix+"startConnected", true)
+
+	return &types.VirtualDeviceConnectInfo{
+		StartConnected:    connected,
+		AllowGuestControl: true,
+		Connected:         connected,
+	}
+}
+
+func (r *vmxDevices) add(device types.BaseVirtualDevice, key, controllerKey, unit int32, label, summary string) {
+	d := device.GetVirtualDevice()
+	d.Key = key
+	d.ControllerKey = controllerKey
+	d.DeviceInfo = &types.Description{Label: label, Summary: summary}
+
+	if unit >= 0 {
+		d.UnitNumber = types.NewInt32(unit)
+	}
+
+	r.devices = append(r.devices, device)
+}
+
+func (r *vmxDevices) readBus(bus vmxBus) {
+	for n := int32(0); n < bus.buses; n++ {
+		prefix := fmt.Sprintf("%s%d.", bus.name, n)
+		controllerKey := bus.controllerKey + n
+		label := fmt.Sprintf("%s controller %d", strings.ToUpper(bus.name), n)
+
+		var controller types.BaseVirtualDevice
+
+		switch bus.name {
+		case "ide":
+			// the IDE controllers are always present
+			label = fmt.Sprintf("IDE %d", n)
+			controller = &types.VirtualIDEController{VirtualController: types.VirtualController{BusNumber: n}}
+		case "scsi":
+			if !r.present(prefix) {
+				continue
+			}
+			controller = scsiController(r.cfg.Get(prefix+"virtualDev"), n)
+		case "sata":
+			if !r.present(prefix) {
+				continue
+			}
+			controller = &types.VirtualAHCIController{VirtualSATAController: types.VirtualSATAController{VirtualController: types.VirtualController{BusNumber: n}}}
+		case "nvme":
+			if !r.present(prefix) {
+				continue
+			}
+			controller = &types.VirtualNVMEController{VirtualController: types.VirtualController{BusNumber: n}}
+		}
+
+		r.add(controller, controllerKey, pciControllerKey, -1, label, label)
+
+		for unit := int32(0); unit < bus.units; unit++ {
+			node := fmt.Sprintf("%s%d:%d", bus.name, n, unit)
+			key := bus.deviceKey + n*bus.units + unit
+
+			if r.present(node + ".") {
+				r.readNode(node, key, controllerKey, unit)
+			}
+		}
+	}
+}
+
+func scsiController(virtualDev string, bus int32) types.BaseVirtualDevice {
+	c := types.VirtualSCSIController{
+		VirtualController:  types.VirtualController{BusNumber: bus},
+		SharedBus:          types.VirtualSCSISharingNoSharing,
+		ScsiCtlrUnitNumber: 7,
+	}
+
+	switch strings.ToLower(virtualDev) {
+	case "lsisas1068":
+		return &types.VirtualLsiLogicSASController{VirtualSCSIController: c}
+	case "pvscsi":
+		return &types.ParaVirtualSCSIController{VirtualSCSIController: c}
+	case "buslogic":
+		return &types.VirtualBusLogicController{VirtualSCSIController: c}
+	default:
+		return &types.VirtualLsiLogicController{VirtualSCSIController: c}
+	}
+}
+
+// readNode reads the disk or CD-ROM attached to a disk controller, other device types are ignored
+func (r *vmxDevices) readNode(node string, key, controllerKey, unit int32) {
+	prefix := node + "."
+	deviceType := strings.ToLower(r.cfg.Get(prefix + "deviceType"))
+	fileName := r.cfg.Get(prefix + "fileName")
+
+	switch {
+	case strings.Contains(deviceType, "cdrom"):
+		cdrom := &types.VirtualCdrom{}
+		cdrom.Connectable = r.connectable(prefix)
+		summary := "Remote device"
+
+		if deviceType == "cdrom-image" {
+			cdrom.Backing = &types.VirtualCdromIsoBackingInfo{
+				VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: resolve(r.path, fileName)},
+			}
+			summary = "ISO " + fileName
+		} else {
+			cdrom.Backing = &types.VirtualCdromAtapiBackingInfo{
+				VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+					DeviceName:    fileName,
+					UseAutoDetect: types.NewBool(r.flag(prefix+"autodetect", false)),
+				},
+			}
+		}
+
+		r.add(cdrom, key, controllerKey, unit, r.label("CD/DVD drive"), summary)
+	// any other device type with a vmdk file is a disk, such as "scsi-hardDisk" or "ata-hardDisk"
+	case strings.HasSuffix(strings.ToLower(fileName), ".vmdk"):
+		disk := &types.VirtualDisk{}
+		backing := diskBacking(resolve(r.path, fileName), 0)
+		backing.DiskMode = string(types.VirtualDiskModePersistent)
+
+		if mode := r.cfg.Get(prefix + "mode"); mode != "" {
+			backing.DiskMode = mode
+		}
+
+		disk.Backing = backing
+
+		if d, err := vmdk.Read(backing.FileName); err == nil {
+			disk.CapacityInBytes = d.Capacity()
+			disk.CapacityInKB = disk.CapacityInBytes / 1024
+		}
+
+		r.add(disk, key, controllerKey, unit, r.label("Hard disk"), units.ByteSize(disk.CapacityInBytes).String())
+	}
+}
+
+// maxDiskChain bounds the parent chain of a disk backing
+const maxDiskChain = 32
+
+// diskBacking returns the backing of a virtual disk file and its parents
+func diskBacking(name string, depth int) *types.VirtualDiskFlatVer2BackingInfo {
+	backing := &types.VirtualDiskFlatVer2BackingInfo{
+		VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: name},
+	}
+
+	d, err := vmdk.Read(name)
+	if err != nil {
+		return backing
+	}
+
+	backing.ThinProvisioned = types.NewBool(strings.Contains(strings.ToLower(d.CreateType), "sparse"))
+
+	if d.ParentFileNameHint != "" && depth < maxDiskChain {
+		backing.Parent = diskBacking(resolve(name, d.ParentFileNameHint), depth+1)
+	}
+
+	return backing
+}
+
+// networkName returns the vmnet of an ethernet adapter, Workstation attaches bridged, host-only
+// and NAT adapters to vmnet0, vmnet1 and vmnet8.
+func networkName(connectionType, vnet string) string {
+	switch strings.ToLower(connectionType) {
+	case "bridged", "":
+		return "vmnet0"
+	case "hostonly":
+		return "vmnet1"
+	case "nat":
+		return "vmnet8"
+	default:
+		// /dev/vmnet2 on Linux, VMnet2 on Windows
+		return strings.ToLower(filepath.Base(vnet))
+	}
+}
+
+func (r *vmxDevices) readEthernet(i int) {
+	prefix := fmt.Sprintf("ethernet%d.", i)
+	if !r.present(prefix) {
+		return
+	}
+
+	var card types.BaseVirtualEthernetCard
+
+	switch strings.ToLower(r.cfg.Get(prefix + "virtualDev")) {
+	case "e1000":
+		card = &types.VirtualE1000{}
+	case "e1000e":
+		card = &types.VirtualE1000e{}
+	case "vmxnet":
+		card = &types.VirtualVmxnet2{}
+	case "vmxnet3":
+		card = &types.VirtualVmxnet3{}
+	default:
+		card = &types.VirtualPCNet32{}
+	}
+
+	nic := card.GetVirtualEthernetCard()
+	nic.Connectable = r.connectable(prefix)
+
+	switch strings.ToLower(r.cfg.Get(prefix + "addressType")) {
+	case "static":
+		nic.AddressType = string(types.VirtualEthernetCardMacTypeManual)
+		nic.MacAddress = r.cfg.Get(prefix + "address")
+	case "vpx":
+		nic.AddressType = string(types.VirtualEthernetCardMacTypeAssigned)
+		nic.MacAddress = r.cfg.Get(prefix + "generatedAddress")
+	default:
+		nic.AddressType = string(types.VirtualEthernetCardMacTypeGenerated)
+		nic.MacAddress = r.cfg.Get(prefix + "generatedAddress")
+	}
+
+	network := networkName(r.cfg.Get(prefix+"connectionType"), r.cfg.Get(prefix+"vnet"))
+
+	nic.Backing = &types.VirtualEthernetCardNetworkBackingInfo{
+		VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{DeviceName: network},
+	}
+
+	r.add(card.(types.BaseVirtualDevice), ethernetDeviceKey+int32(i), pciControllerKey, 7+int32(i), r.label("Network adapter"), network)
+}
+
+func (r *vmxDevices) readUSB() {
+	if r.present("usb.") {
+		usb := &types.VirtualUSBController{
+			AutoConnectDevices: types.NewBool(r.flag("usb.generic.autoconnect", true)),
+			EhciEnabled:        types.NewBool(r.present("ehci.")),
+		}
+
+		r.add(usb, usbControllerKey, pciControllerKey, -1, "USB controller", "Auto connect Enabled")
+	}
+
+	if r.present("usb_xhci.") {
+		r.add(&types.VirtualUSBXHCIController{}, xhciControllerKey, pciControllerKey, -1, "USB xHCI controller", "USB xHCI controller")
+	}
+}
+
+func (r *vmxDevices) readSound() {
+	if !r.present("sound.") {
+		return
+	}
+
+	var card types.BaseVirtualDevice
+
+	switch strings.ToLower(r.cfg.Get("sound.virtualDev")) {
+	case "hdaudio":
+		card = &types.VirtualHdAudioCard{}
+	case "sb16":
+		card = &types.VirtualSoundBlaster16{}
+	default:
+		card = &types.VirtualEnsoniq1371{}
+	}
+
+	d := card.GetVirtualDevice()
+	d.Connectable = r.connectable("sound.")
+	d.Backing = &types.VirtualSoundCardDeviceBackingInfo{
+		VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{
+			DeviceName:    r.cfg.Get("sound.fileName"),
+			UseAutoDetect: types.NewBool(r.flag("sound.autodetect", false)),
+		},
+	}
+
+	r.add(card, soundDeviceKey, pciControllerKey, -1, "Sound card", "Sound card")
+}
+
+func (r *vmxDevices) readSerial(i int) {
+	prefix := fmt.Sprintf("serial%d.", i)
+	if !r.present(prefix) {
+		return
+	}
+
+	port := &types.VirtualSerialPort{YieldOnPoll: true}
+	port.Connectable = r.connectable(prefix)
+	fileName := r.cfg.Get(prefix + "fileName")
+	summary := "Serial port"
+
+	switch strings.ToLower(r.cfg.Get(prefix + "fileType")) {
+	case "file":
+		port.Backing = &types.VirtualSerialPortFileBackingInfo{
+			VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: resolve(r.path, fileName)},
+		}
+		summary = "File " + fileName
+	case "device":
+		port.Backing = &types.VirtualSerialPortDeviceBackingInfo{
+			VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{DeviceName: fileName},
+		}
+		summary = "Device " + fileName
+	case "pipe":
+		endpoint := "server"
+		if strings.EqualFold(r.cfg.Get(prefix+"pipe.endPoint"), "client") {
+			endpoint = "client"
+		}
+
+		port.Backing = &types.VirtualSerialPortPipeBackingInfo{
+			VirtualDevicePipeBackingInfo: types.VirtualDevicePipeBackingInfo{PipeName: fileName},
+			Endpoint:                     endpoint,
+		}
+		summary = "Pipe " + fileName
+	}
+
+	r.add(port, serialDeviceKey+int32(i), sioControllerKey, int32(i), r.label("Serial port"), summary)
+}
+
+func (r *vmxDevices) readFloppy(i int) {
+	prefix := fmt.Sprintf("floppy%d.", i)
+	if !r.present(prefix) {
+		return
+	}
+
+	floppy := &types.VirtualFloppy{}
+	floppy.Connectable = r.connectable(prefix)
+	fileName := r.cfg.Get(prefix + "fileName")
+	summary := "Remote device"
+
+	if strings.EqualFold(r.cfg.Get(prefix+"fileType"), "file") {
+		floppy.Backing = &types.VirtualFloppyImageBackingInfo{
+			VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: resolve(r.path, fileName)},
+		}
+		summary = "Image " + fileName
+	} else {
+		floppy.Backing = &types.VirtualFloppyDeviceBackingInfo{
+			VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{DeviceName: fileName},
+		}
+	}
+
+	r.add(floppy, floppyDeviceKey+int32(i), sioControllerKey, int32(i), r.label("Floppy drive"), summary)
+}
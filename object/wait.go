@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"context"
+	"time"
+)
+
+// Backoff configures how vmrest is polled while waiting for a condition,
+// the delay between two polls starts at Initial and is multiplied by Factor up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	Factor  float64
+}
+
+// DefaultBackoff is used by tasks and the Wait methods of VirtualMachine
+var DefaultBackoff = Backoff{
+	Initial: 250 * time.Millisecond,
+	Max:     5 * time.Second,
+	Factor:  2,
+}
+
+// Poll calls f until it returns true or an error, or the context is done.
+// The zero fields of the Backoff default to the DefaultBackoff values.
+func (b Backoff) Poll(ctx context.Context, f func(context.Context) (bool, error)) error {
+	if b.Initial <= 0 {
+		b.Initial = DefaultBackoff.Initial
+	}
+
+	if b.Max <= 0 {
+		b.Max = DefaultBackoff.Max
+
+		if b.Initial > b.Max {
+			b.Max = b.Initial
+		}
+	}
+
+	if b.Factor <= 0 {
+		b.Factor = DefaultBackoff.Factor
+	}
+
+	delay := b.Initial
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		done, err := f(ctx)
+		if err != nil || done {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if b.Factor > 1 {
+			delay = time.Duration(float64(delay) * b.Factor)
+		}
+
+		if delay > b.Max {
+			delay = b.Max
+		}
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Fred78290/govmrest/object"
+)
+
+func TestBackoffPoll(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// a zero Backoff polls with the DefaultBackoff delays
+	calls := 0
+
+	err := object.Backoff{}.Poll(ctx, func(context.Context) (bool, error) {
+		calls++
+		return false, nil
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	// 0, 250ms and 750ms
+	if calls > 4 {
+		t.Errorf("expected at most 4 polls, got %d", calls)
+	}
+
+	calls = 0
+
+	err = object.Backoff{Initial: time.Millisecond, Max: time.Millisecond}.Poll(context.Background(), func(context.Context) (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected 3 polls, got %d: %v", calls, err)
+	}
+}
@@ -20,8 +20,10 @@ package vim25
 
 import (
 	"context"
+	"net/url"
 
 	"github.com/Fred78290/vmrest-go-client/client"
+	"github.com/Fred78290/vmrest-go-client/client/model"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -36,7 +38,29 @@ type Client struct {
 func NewClient(ctx context.Context, client *client.APIClient) (*Client, error) {
 	c := Client{
 		APIClient: client,
+		ServiceContent: types.ServiceContent{
+			RootFolder: types.ManagedObjectReference{
+				Type:  "Folder",
+				Value: "RootFolder",
+			},
+			SearchIndex: &types.ManagedObjectReference{
+				Type:  "SearchIndex",
+				Value: "SearchIndex",
+			},
+		},
 	}
 
 	return &c, nil
 }
+
+// UpdateNetwork updates the type, DHCP, subnet and mask of the virtual network vmnet.
+// vmrest-go-client has no binding for PUT /vmnet/{vmnet}.
+func (c *Client) UpdateNetwork(vmnet string, parameters *model.Network) (*model.Network, error) {
+	var res model.Network
+
+	if err := c.APIClient.Client.Put("/api/vmnet/"+url.PathEscape(vmnet), parameters, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
@@ -20,23 +20,194 @@ package methods
 
 import (
 	"context"
+	"net"
+	"path"
+	"strings"
 
 	"github.com/Fred78290/govmrest/vim25"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
+func vmReference(id string) *types.ManagedObjectReference {
+	return &types.ManagedObjectReference{
+		Type:  "VirtualMachine",
+		Value: id,
+	}
+}
+
+// cleanPath normalizes a .vmx path so paths using either separator compare equal.
+func cleanPath(p string) string {
+	return path.Clean(strings.ReplaceAll(p, "\\", "/"))
+}
+
+// normalizeUUID strips the separators used by uuid.bios ("56 4d 3a 5e ...-...") and canonical UUIDs.
+func normalizeUUID(uuid string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(uuid))
+}
+
+// normalizeMAC returns the canonical lower case form of a MAC address or the input if it can't be parsed.
+func normalizeMAC(mac string) string {
+	if hw, err := net.ParseMAC(mac); err == nil {
+		return hw.String()
+	}
+
+	return strings.ToLower(mac)
+}
+
+// guestIP returns the IP address reported by VMware Tools, an empty string if the VM has no IP.
+func guestIP(c *vim25.Client, id string) string {
+	res, err := c.GetIPAddress(id)
+	if err != nil {
+		return ""
+	}
+
+	return res.Ip
+}
+
+// FindByDatastorePath finds a virtual machine by the path of its .vmx file.
 func FindByDatastorePath(ctx context.Context, c *vim25.Client, req *types.FindByDatastorePath) (*types.FindByDatastorePathResponse, error) {
-	return nil, nil
+	vmids, err := c.GetAllVMs()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.FindByDatastorePathResponse{}
+	p := cleanPath(req.Path)
+
+	for _, vmid := range vmids {
+		if cleanPath(vmid.Path) == p {
+			res.Returnval = vmReference(vmid.Id)
+			break
+		}
+	}
+
+	return res, nil
 }
 
+// FindByDnsName finds a virtual machine whose guest IP address resolves to the given DNS name.
 func FindByDnsName(ctx context.Context, c *vim25.Client, req *types.FindByDnsName) (*types.FindByDnsNameResponse, error) {
-	return nil, nil
+	vmids, err := c.GetAllVMs()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.FindByDnsNameResponse{}
+	dnsName := strings.TrimSuffix(req.DnsName, ".")
+
+	for _, vmid := range vmids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		ip := guestIP(c, vmid.Id)
+		if ip == "" {
+			continue
+		}
+
+		names, err := net.DefaultResolver.LookupAddr(ctx, ip)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range names {
+			if strings.EqualFold(strings.TrimSuffix(name, "."), dnsName) {
+				res.Returnval = vmReference(vmid.Id)
+				return res, nil
+			}
+		}
+	}
+
+	return res, nil
 }
 
+// FindByIp finds a virtual machine by IP address, either reported by VMware Tools
+// or reserved for one of its NIC in the vmnet MAC to IP tables.
 func FindByIp(ctx context.Context, c *vim25.Client, req *types.FindByIp) (*types.FindByIpResponse, error) {
-	return nil, nil
+	vmids, err := c.GetAllVMs()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.FindByIpResponse{}
+
+	for _, vmid := range vmids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if guestIP(c, vmid.Id) == req.Ip {
+			res.Returnval = vmReference(vmid.Id)
+			return res, nil
+		}
+	}
+
+	// VMware Tools is not running, look for a DHCP reservation
+	vmnets, err := c.GetAllNetworks()
+	if err != nil {
+		return nil, err
+	}
+
+	macs := map[string]bool{}
+
+	for _, vmnet := range vmnets.Vmnets {
+		mactoips, err := c.GetMACToIPs(vmnet.Name)
+		if err != nil {
+			continue
+		}
+
+		for _, mactoip := range mactoips.Mactoips {
+			if mactoip.Ip == req.Ip {
+				macs[normalizeMAC(mactoip.Mac)] = true
+			}
+		}
+	}
+
+	if len(macs) == 0 {
+		return res, nil
+	}
+
+	for _, vmid := range vmids {
+		nics, err := c.GetAllNICDevices(vmid.Id)
+		if err != nil {
+			continue
+		}
+
+		for _, nic := range nics.Nics {
+			if macs[normalizeMAC(nic.MacAddress)] {
+				res.Returnval = vmReference(vmid.Id)
+				return res, nil
+			}
+		}
+	}
+
+	return res, nil
 }
 
+// FindByUuid finds a virtual machine by its BIOS UUID, the uuid.bios value of the .vmx file.
 func FindByUuid(ctx context.Context, c *vim25.Client, req *types.FindByUuid) (*types.FindByUuidResponse, error) {
-	return nil, nil
+	vmids, err := c.GetAllVMs()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &types.FindByUuidResponse{}
+	uuid := normalizeUUID(req.Uuid)
+
+	for _, vmid := range vmids {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		param, err := c.GetVMParams(vmid.Id, "uuid.bios")
+		if err != nil {
+			continue
+		}
+
+		if normalizeUUID(param.Value) == uuid {
+			res.Returnval = vmReference(vmid.Id)
+			break
+		}
+	}
+
+	return res, nil
 }
@@ -0,0 +1,170 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vim25
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// MediaType is the content type used by the vmrest API.
+const MediaType = "application/vnd.vmware.vmw.rest-v1+json"
+
+// Fault is the error returned when vmrest replies with an error status.
+type Fault struct {
+	StatusCode int
+	model.ErrorModel
+}
+
+func (f *Fault) Error() string {
+	if f.Message != "" {
+		return f.Message
+	}
+
+	return http.StatusText(f.StatusCode)
+}
+
+// IsFault returns true if err is a Fault with the given HTTP status code.
+func IsFault(err error, statusCode int) bool {
+	if f, ok := err.(*Fault); ok {
+		return f.StatusCode == statusCode
+	}
+
+	return false
+}
+
+// RESTClient implements the vmrest-go-client api.Client interface.
+// Unlike the default implementation, the timeout is honoured as a time.Duration and
+// responses using the vmrest media type are decoded.
+type RESTClient struct {
+	UserAgent string
+
+	u *url.URL
+	c *http.Client
+}
+
+// NewRESTClient returns a client for the vmrest endpoint u, the credentials are taken from u.User.
+func NewRESTClient(u *url.URL, timeout time.Duration) *RESTClient {
+	return &RESTClient{
+		UserAgent: "govmrest/1.0.0/go",
+		u:         u,
+		c: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// URL returns the vmrest endpoint
+func (c *RESTClient) URL() *url.URL {
+	return c.u
+}
+
+// Do sends a request to vmrest, reqBody is encoded as JSON and the response is decoded into resType when not nil.
+func (c *RESTClient) Do(ctx context.Context, method, path string, reqBody, resType interface{}) error {
+	var body io.Reader
+
+	if reqBody != nil {
+		b, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	u := *c.u
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", MediaType)
+	}
+
+	req.Header.Set("Accept", MediaType)
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	if c.u.User != nil {
+		password, _ := c.u.User.Password()
+		req.SetBasicAuth(c.u.User.Username(), password)
+	}
+
+	res, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		fault := &Fault{StatusCode: res.StatusCode}
+		_ = json.Unmarshal(b, &fault.ErrorModel)
+		return fault
+	}
+
+	if resType == nil || len(b) == 0 {
+		return nil
+	}
+
+	if !strings.Contains(res.Header.Get("Content-Type"), "json") {
+		return fmt.Errorf("unexpected content type %q", res.Header.Get("Content-Type"))
+	}
+
+	return json.Unmarshal(b, resType)
+}
+
+// Get is a wrapper for the GET method
+func (c *RESTClient) Get(path string, resType interface{}) error {
+	return c.Do(context.Background(), http.MethodGet, path, nil, resType)
+}
+
+// Patch is a wrapper for the PATCH method
+func (c *RESTClient) Patch(path string, reqBody, resType interface{}) error {
+	return c.Do(context.Background(), http.MethodPatch, path, reqBody, resType)
+}
+
+// Post is a wrapper for the POST method
+func (c *RESTClient) Post(path string, reqBody, resType interface{}) error {
+	return c.Do(context.Background(), http.MethodPost, path, reqBody, resType)
+}
+
+// Put is a wrapper for the PUT method
+func (c *RESTClient) Put(path string, reqBody, resType interface{}) error {
+	return c.Do(context.Background(), http.MethodPut, path, reqBody, resType)
+}
+
+// Delete is a wrapper for the DELETE method
+func (c *RESTClient) Delete(path string, resType interface{}) error {
+	return c.Do(context.Background(), http.MethodDelete, path, nil, resType)
+}
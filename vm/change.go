@@ -25,8 +25,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/Fred78290/govmrest/flags"
 	"github.com/vmware/govmomi/govc/cli"
-	"github.com/vmware/govmomi/govc/flags"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -71,7 +71,8 @@ func (e *extraConfigFile) Set(v string) error {
 }
 
 type change struct {
-	*flags.VirtualMachineFlag
+	*flags.ClientFlag
+	*flags.SearchFlag
 	*flags.ResourceAllocationFlag
 
 	types.VirtualMachineConfigSpec
@@ -98,8 +99,14 @@ var hwUpgradePolicies = []string{
 }
 
 func (cmd *change) Register(ctx context.Context, f *flag.FlagSet) {
-	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
-	cmd.VirtualMachineFlag.Register(ctx, f)
+	// the spec and the ExtraConfig values are not reset by the flags when the command runs again
+	*cmd = change{}
+
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.SearchFlag, ctx = flags.NewSearchFlag(ctx, flags.SearchVirtualMachines)
+	cmd.SearchFlag.Register(ctx, f)
 
 	cmd.CpuAllocation = &types.ResourceAllocationInfo{Shares: new(types.SharesInfo)}
 	cmd.MemoryAllocation = &types.ResourceAllocationInfo{Shares: new(types.SharesInfo)}
@@ -128,34 +135,133 @@ func (cmd *change) Register(ctx context.Context, f *flag.FlagSet) {
 	f.StringVar(&cmd.hwUpgradePolicy, "scheduled-hw-upgrade-policy", "", fmt.Sprintf("Schedule hardware upgrade policy (%s)", strings.Join(hwUpgradePolicies, "|")))
 }
 
+func (cmd *change) Usage() string {
+	return "VM..."
+}
+
 func (cmd *change) Description() string {
 	return `Change VM configuration.
 
 To add ExtraConfig variables that can read within the guest, use the 'guestinfo.' prefix.
 
+The memory size and the number of CPUs can only be changed while the VM is powered off.
+The settings are written to the vmx file when the VM is powered off and its files are local,
+an ExtraConfig variable with an empty value is then removed.
+Latency sensitivity, hardware upgrade policy and resource allocations are not supported by Workstation.
+
 Examples:
-  govc vm.change -vm $vm -mem.reservation 2048
-  govc vm.change -vm $vm -e smc.present=TRUE -e ich7m.present=TRUE
+  govc vm.change -m 2048 -c 2 $vm
+  govc vm.change -e smc.present=TRUE -e ich7m.present=TRUE $vm
   # Enable both cpu and memory hotplug on a guest:
-  govc vm.change -vm $vm -cpu-hot-add-enabled -memory-hot-add-enabled
-  govc vm.change -vm $vm -e guestinfo.vmname $vm
+  govc vm.change -cpu-hot-add-enabled -memory-hot-add-enabled $vm
+  govc vm.change -e guestinfo.vmname=$vm $vm
+  # Remove the variable from the vmx of a powered off VM:
+  govc vm.change -e guestinfo.vmname= $vm
   # Read the contents of a file and use them as ExtraConfig value
-  govc vm.change -vm $vm -f guestinfo.data="$(realpath .)/vmdata.config"
+  govc vm.change -f guestinfo.data="$(realpath .)/vmdata.config" $vm
   # Read the variable set above inside the guest:
   vmware-rpctool "info-get guestinfo.vmname"
-  govc vm.change -vm $vm -latency high
-  govc vm.change -vm $vm -latency normal
-  govc vm.change -vm $vm -uuid 4139c345-7186-4924-a842-36b69a24159b
-  govc vm.change -vm $vm -scheduled-hw-upgrade-policy always`
+  govc vm.change -nested-hv-enabled $vm
+  govc vm.change -uuid 4139c345-7186-4924-a842-36b69a24159b $vm`
 }
 
 func (cmd *change) Process(ctx context.Context) error {
-	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.SearchFlag.Process(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (cmd *change) setAllocation(alloc **types.ResourceAllocationInfo) {
+	if (*alloc).Shares.Level == "" {
+		(*alloc).Shares = nil
+	}
+
+	if (*alloc).Shares == nil && (*alloc).Limit == nil && (*alloc).Reservation == nil {
+		*alloc = nil
+	}
+}
+
+func (cmd *change) setLatency() error {
+	if cmd.Latency == "" {
+		return nil
+	}
+
+	for _, l := range latencyLevels {
+		if l == cmd.Latency {
+			cmd.LatencySensitivity = &types.LatencySensitivity{
+				Level: types.LatencySensitivitySensitivityLevel(cmd.Latency),
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("latency must be one of: %s", strings.Join(latencyLevels, "|"))
+}
+
+func (cmd *change) setHwUpgradePolicy() error {
+	if cmd.hwUpgradePolicy == "" {
+		return nil
+	}
+
+	for _, p := range hwUpgradePolicies {
+		if p == cmd.hwUpgradePolicy {
+			cmd.ScheduledHardwareUpgradeInfo = &types.ScheduledHardwareUpgradeInfo{
+				UpgradePolicy: p,
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("scheduled-hw-upgrade-policy must be one of: %s", strings.Join(hwUpgradePolicies, "|"))
+}
+
 func (cmd *change) Run(ctx context.Context, f *flag.FlagSet) error {
+	vms, err := cmd.VirtualMachines(f.Args())
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.extraConfig) > 0 {
+		cmd.VirtualMachineConfigSpec.ExtraConfig = cmd.extraConfig
+	}
+
+	if len(cmd.extraConfigFile) > 0 {
+		cmd.VirtualMachineConfigSpec.ExtraConfig = append(cmd.VirtualMachineConfigSpec.ExtraConfig, cmd.extraConfigFile...)
+	}
+
+	if cmd.Tools.SyncTimeWithHost == nil {
+		cmd.Tools = nil
+	}
+
+	cmd.setAllocation(&cmd.CpuAllocation)
+	cmd.setAllocation(&cmd.MemoryAllocation)
+
+	if err = cmd.setLatency(); err != nil {
+		return err
+	}
+
+	if err = cmd.setHwUpgradePolicy(); err != nil {
+		return err
+	}
+
+	if cmd.Name != "" && len(vms) > 1 {
+		return fmt.Errorf("-name cannot be used with multiple VMs")
+	}
+
+	for _, vm := range vms {
+		task, err := vm.Reconfigure(ctx, cmd.VirtualMachineConfigSpec)
+		if err != nil {
+			return err
+		}
+
+		if err = task.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,94 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+)
+
+func TestChange(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM0")
+
+		if _, err := govc(t, c, "vm.change", "-c", "2", "VM0"); err == nil {
+			t.Error("expected an error changing the CPUs of a powered on VM")
+		}
+
+		if _, err := govc(t, c, "vm.power", "-off", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := govc(t, c, "vm.change", "-c", "2", "-m", "2048", "-g", "debian11-64",
+			"-annotation", "test VM", "-e", "guestinfo.foo=bar", "-nested-hv-enabled=true", "VM0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := loadVMX(ctx, t, vm)
+
+		for key, value := range map[string]string{
+			"numvcpus":      "2",
+			"memsize":       "2048",
+			"guestOS":       "debian11-64",
+			"annotation":    "test VM",
+			"guestinfo.foo": "bar",
+			"vhv.enable":    "TRUE",
+		} {
+			if cfg.Get(key) != value {
+				t.Errorf("%s: expected %q, got %q", key, value, cfg.Get(key))
+			}
+		}
+
+		// the settings of the previous run are not applied again
+		cfg.Set("numvcpus", "4")
+		cfg.Set("guestinfo.foo", "baz")
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = cfg.Save(vmxPath); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "vm.change", "-m", "4096", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg = loadVMX(ctx, t, vm)
+
+		for key, value := range map[string]string{
+			"numvcpus":      "4",
+			"memsize":       "4096",
+			"guestOS":       "debian11-64",
+			"guestinfo.foo": "baz",
+		} {
+			if cfg.Get(key) != value {
+				t.Errorf("%s: expected %q, got %q", key, value, cfg.Get(key))
+			}
+		}
+
+		if _, err = govc(t, c, "vm.change", "-latency", "high", "VM0"); err == nil {
+			t.Error("expected an error setting the latency sensitivity")
+		}
+	})
+}
@@ -20,19 +20,36 @@ package vm
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
 
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
 	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 type clone struct {
+	*flags.OutputFlag
+	*flags.ClientFlag
+	*flags.SearchFlag
+
 	name          string
 	memory        int
 	cpus          int
 	on            bool
 	force         bool
+	link          bool
 	customization string
 	waitForIP     bool
+
+	Client         *vim25.Client
+	VirtualMachine *object.VirtualMachine
 }
 
 func init() {
@@ -40,10 +57,20 @@ func init() {
 }
 
 func (cmd *clone) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.SearchFlag, ctx = flags.NewSearchFlag(ctx, flags.SearchVirtualMachines)
+	cmd.SearchFlag.Register(ctx, f)
+
 	f.IntVar(&cmd.memory, "m", 0, "Size in MB of memory")
 	f.IntVar(&cmd.cpus, "c", 0, "Number of CPUs")
 	f.BoolVar(&cmd.on, "on", true, "Power on VM")
 	f.BoolVar(&cmd.force, "force", false, "Create VM if vmx already exists")
+	f.BoolVar(&cmd.link, "link", false, "Creates a linked clone from the current snapshot of the source VM")
 	f.StringVar(&cmd.customization, "customization", "", "Customization Specification Name")
 	f.BoolVar(&cmd.waitForIP, "waitip", false, "Wait for VM to acquire IP address")
 }
@@ -55,15 +82,39 @@ func (cmd *clone) Usage() string {
 func (cmd *clone) Description() string {
 	return `Clone VM to NAME.
 
+The clone is created in a NAME directory next to the source VM directory.
+If a VM already exists there, registered or not, it is destroyed first when -force is set.
+
+A linked clone shares the disks of the current snapshot of the source VM, which is taken first
+if the source VM has no snapshot: the source VM must then be powered off. The source VM files
+must be reachable from this host as the vmx files are edited locally. The source VM cannot be
+destroyed while it has linked clones.
+
 Examples:
-  govc vm.clone -vm template-vm new-vm`
+  govc vm.clone -vm.ipath template-vm new-vm
+  govc vm.clone -vm.ipath template-vm -m 4096 -c 2 -waitip -json new-vm
+  govc vm.clone -vm.ipath template-vm -link ci-vm-1`
 }
 
 func (cmd *clone) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.SearchFlag.Process(ctx); err != nil {
+		return err
+	}
+	if cmd.customization != "" {
+		return fmt.Errorf("-customization: %w", object.ErrNotSupported)
+	}
 	return nil
 }
 
 func (cmd *clone) Run(ctx context.Context, f *flag.FlagSet) error {
+	var err error
+
 	if len(f.Args()) != 1 {
 		return flag.ErrHelp
 	}
@@ -73,5 +124,139 @@ func (cmd *clone) Run(ctx context.Context, f *flag.FlagSet) error {
 		return flag.ErrHelp
 	}
 
-	return nil
+	if !cmd.SearchFlag.IsSet() {
+		return flag.ErrHelp
+	}
+
+	if cmd.Client, err = cmd.ClientFlag.Client(); err != nil {
+		return err
+	}
+
+	if cmd.VirtualMachine, err = cmd.SearchFlag.VirtualMachine(); err != nil {
+		return err
+	}
+
+	vmxPath, err := cmd.VirtualMachine.VmxPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	// the clone is created in a directory next to the source VM directory
+	vmxPath = filepath.Join(filepath.Dir(filepath.Dir(vmxPath)), cmd.name, cmd.name+".vmx")
+
+	if err = removeExisting(ctx, cmd.Client, vmxPath, cmd.force); err != nil {
+		return err
+	}
+
+	vm, err := cmd.cloneVM(ctx)
+	if err != nil {
+		return err
+	}
+
+	var ip string
+
+	if cmd.on && cmd.waitForIP {
+		err = cmd.WithCancel(ctx, func(ctx context.Context) error {
+			ip, err = vm.WaitForIP(ctx)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !cmd.All() {
+		return nil
+	}
+
+	return cmd.WriteResult(&cloneResult{cmd: cmd, vm: vm, ip: ip})
+}
+
+func (cmd *clone) cloneVM(ctx context.Context) (*object.VirtualMachine, error) {
+	spec := types.VirtualMachineCloneSpec{
+		PowerOn: cmd.on,
+		Config: &types.VirtualMachineConfigSpec{
+			NumCPUs:  int32(cmd.cpus),
+			MemoryMB: int64(cmd.memory),
+		},
+	}
+
+	if cmd.link {
+		spec.Location.DiskMoveType = string(types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking)
+	}
+
+	task, err := cmd.VirtualMachine.Clone(ctx, nil, cmd.name, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := cmd.ProgressLogger(fmt.Sprintf("Cloning %s to %s...", cmd.VirtualMachine.Reference(), cmd.name))
+	defer logger.Wait()
+
+	info, err := task.WaitForResult(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	finder, err := cmd.Finder()
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := finder.ObjectReference(ctx, info.Result.(types.ManagedObjectReference))
+	if err != nil {
+		return nil, err
+	}
+
+	return ref.(*object.VirtualMachine), nil
+}
+
+type cloneResult struct {
+	cmd *clone
+	vm  *object.VirtualMachine
+	ip  string
+}
+
+func (r *cloneResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Dump())
+}
+
+func (r *cloneResult) Dump() interface{} {
+	ctx := context.TODO()
+
+	res := struct {
+		Self          types.ManagedObjectReference
+		Name          string
+		InventoryPath string
+		NumCPU        int
+		MemoryMB      int
+		PowerState    types.VirtualMachinePowerState
+		IpAddress     string
+	}{
+		Self:          r.vm.Reference(),
+		Name:          r.vm.Name(),
+		InventoryPath: r.vm.InventoryPath,
+		IpAddress:     r.ip,
+	}
+
+	if info, err := r.cmd.Client.GetVM(r.vm.Reference().Value); err == nil {
+		if info.Cpu != nil {
+			res.NumCPU = info.Cpu.Processors
+		}
+		res.MemoryMB = info.Memory
+	}
+
+	res.PowerState, _ = r.vm.PowerState(ctx)
+
+	return res
+}
+
+func (r *cloneResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Name:\t%s\n", r.vm.Name())
+	fmt.Fprintf(tw, "  Path:\t%s\n", r.vm.InventoryPath)
+	fmt.Fprintf(tw, "  IP address:\t%s\n", r.ip)
+
+	return tw.Flush()
 }
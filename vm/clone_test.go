@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestClone(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		if _, err := govc(t, c, "vm.clone", "VM2"); err == nil {
+			t.Error("expected an error without a source VM")
+		}
+
+		out, err := govc(t, c, "vm.clone", "-vm.ipath", "VM0", "-c", "2", "-m", "2048", "-json", "VM2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res struct {
+			Name       string
+			NumCPU     int
+			MemoryMB   int
+			PowerState types.VirtualMachinePowerState
+		}
+
+		if err = json.Unmarshal([]byte(out), &res); err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Name != "VM2" || res.NumCPU != 2 || res.MemoryMB != 2048 || res.PowerState != types.VirtualMachinePowerStatePoweredOn {
+			t.Errorf("unexpected clone: %+v", res)
+		}
+
+		if _, err = govc(t, c, "vm.clone", "-vm.ipath", "VM0", "VM2"); err == nil {
+			t.Error("expected an error cloning to an existing VM")
+		}
+
+		if _, err = govc(t, c, "vm.clone", "-vm.ipath", "VM0", "-on=false", "-force", "VM2"); err != nil {
+			t.Fatal(err)
+		}
+
+		// the destroyed VM was powered on
+		vm := findVM(ctx, t, c, "VM2")
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOff)
+
+		// the files of an unregistered VM are destroyed too
+		if err = vm.Unregister(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "vm.clone", "-vm.ipath", "VM0", "-on=false", "VM2"); err == nil {
+			t.Error("expected an error cloning to the files of an unregistered VM")
+		}
+
+		if _, err = govc(t, c, "vm.clone", "-vm.ipath", "VM0", "-on=false", "-force", "VM2"); err != nil {
+			t.Fatal(err)
+		}
+
+		findVM(ctx, t, c, "VM2")
+
+		if _, err = govc(t, c, "vm.clone", "-vm.ipath", "VM0", "-link", "VM3"); err == nil {
+			t.Error("expected an error linking to a powered on VM without snapshot")
+		}
+
+		if _, err = govc(t, c, "vm.power", "-off", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "vm.clone", "-vm.ipath", "VM0", "-link", "VM3"); err != nil {
+			t.Fatal(err)
+		}
+
+		devices, err := findVM(ctx, t, c, "VM3").Device(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		disk := devices.SelectByType((*types.VirtualDisk)(nil))[0].(*types.VirtualDisk)
+		if disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).Parent == nil {
+			t.Error("expected a linked clone disk")
+		}
+
+		if _, err = govc(t, c, "vm.clone", "-vm.ipath", "VM0", "-customization", "spec", "VM4"); err == nil {
+			t.Error("expected an error with -customization")
+		}
+	})
+}
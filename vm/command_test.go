@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+func findVM(ctx context.Context, t *testing.T, c *vim25.Client, name string) *object.VirtualMachine {
+	t.Helper()
+
+	vm, err := find.NewFinder(c).VirtualMachine(ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return vm
+}
+
+func powerState(ctx context.Context, t *testing.T, vm *object.VirtualMachine, expect types.VirtualMachinePowerState) {
+	t.Helper()
+
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state != expect {
+		t.Errorf("expected %s, got %s", expect, state)
+	}
+}
+
+func loadVMX(ctx context.Context, t *testing.T, vm *object.VirtualMachine) *vmx.File {
+	t.Helper()
+
+	vmxPath, err := vm.VmxPath(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := vmx.Load(vmxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg
+}
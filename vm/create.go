@@ -0,0 +1,343 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govc sources https://github.com/vmware/govmomi/govc
+*/
+
+package vm
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/units"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var (
+	FirmwareTypes = []string{
+		string(types.GuestOsDescriptorFirmwareTypeBios),
+		string(types.GuestOsDescriptorFirmwareTypeEfi),
+	}
+
+	FirmwareUsage = fmt.Sprintf("Firmware type [%s]", strings.Join(FirmwareTypes, "|"))
+)
+
+type create struct {
+	*flags.OutputFlag
+	*flags.ClientFlag
+	*flags.NetworkFlag
+
+	name       string
+	dir        string
+	memory     int
+	cpus       int
+	guestID    string
+	link       bool
+	on         bool
+	force      bool
+	controller string
+	annotation string
+	firmware   string
+	version    string
+
+	iso string
+
+	disk         string
+	diskByteSize int64
+
+	Client *vim25.Client
+}
+
+func init() {
+	cli.Register("vm.create", &create{})
+}
+
+func (cmd *create) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.NetworkFlag, ctx = flags.NewNetworkFlag(ctx)
+	cmd.NetworkFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.dir, "dir", object.DefaultVMDirectory(), "Directory of the VM directory")
+	f.IntVar(&cmd.memory, "m", 1024, "Size in MB of memory")
+	f.IntVar(&cmd.cpus, "c", 1, "Number of CPUs")
+	f.StringVar(&cmd.guestID, "g", "other-64", "Guest OS ID")
+	f.BoolVar(&cmd.link, "link", true, "Link specified disk")
+	f.BoolVar(&cmd.on, "on", true, "Power on VM")
+	f.BoolVar(&cmd.force, "force", false, "Create VM if vmx already exists")
+	f.StringVar(&cmd.controller, "disk.controller", "scsi", "Disk controller type")
+	f.StringVar(&cmd.annotation, "annotation", "", "VM description")
+	f.StringVar(&cmd.firmware, "firmware", FirmwareTypes[0], FirmwareUsage)
+	f.StringVar(&cmd.version, "version", "", "Hardware version")
+	f.StringVar(&cmd.iso, "iso", "", "ISO path")
+	f.StringVar(&cmd.disk, "disk", "", "Disk path (to use existing) OR size (to create new, e.g. 20GB)")
+}
+
+func (cmd *create) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.NetworkFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *create) Usage() string {
+	return "NAME"
+}
+
+func (cmd *create) Description() string {
+	return `Create VM.
+
+The VM files are created in the NAME directory of the -dir directory, which must be reachable from this host.
+If a VM already exists there, registered or not, it is destroyed first when -force is set.
+The VM is kept when it cannot be powered on with -on, the error names its .vmx file.
+
+An existing disk is linked by default: the VM disk is a child of the given disk, which is left unchanged.
+The -disk.controller is scsi (or a SCSI controller type such as pvscsi), sata, nvme or ide.
+
+Examples:
+  govc vm.create -on=false vm-name
+  govc vm.create -disk 20GB -iso ~/iso/ubuntu-22.04-live-server-amd64.iso -g ubuntu-64 vm-name
+  govc vm.create -disk ~/vmware/template/template.vmdk -net vmnet2 vm-name
+  govc vm.create -m 2048 -c 2 -g freebsd-64 -net.adapter vmxnet3 -disk.controller pvscsi vm-name`
+}
+
+func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
+	var err error
+
+	if len(f.Args()) != 1 {
+		return flag.ErrHelp
+	}
+
+	cmd.name = f.Arg(0)
+	if cmd.name == "" {
+		return flag.ErrHelp
+	}
+
+	if cmd.Client, err = cmd.ClientFlag.Client(); err != nil {
+		return err
+	}
+
+	// Verify ISO exists
+	if cmd.iso != "" {
+		if cmd.iso, err = filepath.Abs(cmd.iso); err != nil {
+			return err
+		}
+
+		if _, err = os.Stat(cmd.iso); err != nil {
+			return err
+		}
+	}
+
+	// Verify disk exists
+	cmd.diskByteSize = 0
+	if cmd.disk != "" {
+		var b units.ByteSize
+
+		// If disk can be parsed as byte units, don't stat
+		err = b.Set(cmd.disk)
+		if err == nil {
+			cmd.diskByteSize = int64(b)
+		} else {
+			if cmd.disk, err = filepath.Abs(cmd.disk); err != nil {
+				return err
+			}
+
+			if _, err = os.Stat(cmd.disk); err != nil {
+				return err
+			}
+		}
+	}
+
+	vmxPath, err := filepath.Abs(filepath.Join(cmd.dir, cmd.name, cmd.name+".vmx"))
+	if err != nil {
+		return err
+	}
+
+	if err = removeExisting(ctx, cmd.Client, vmxPath, cmd.force); err != nil {
+		return err
+	}
+
+	task, err := cmd.createVM(ctx, vmxPath)
+	if err != nil {
+		return err
+	}
+
+	info, err := task.WaitForResult(ctx)
+	if err != nil {
+		return err
+	}
+
+	vm := object.NewVirtualMachine(cmd.Client, info.Result.(types.ManagedObjectReference))
+
+	if cmd.on {
+		task, err := vm.PowerOn(ctx)
+		if err != nil {
+			return fmt.Errorf("%s is created, powering it on failed: %w", vmxPath, err)
+		}
+
+		logger := cmd.ProgressLogger(fmt.Sprintf("Powering on %s...", cmd.name))
+		defer logger.Wait()
+
+		if _, err = task.WaitForResult(ctx, logger); err != nil {
+			return fmt.Errorf("%s is created, powering it on failed: %w", vmxPath, err)
+		}
+	}
+
+	return nil
+}
+
+func (cmd *create) createVM(ctx context.Context, vmxPath string) (*object.Task, error) {
+	var devices object.VirtualDeviceList
+	var err error
+
+	spec := &types.VirtualMachineConfigSpec{
+		Name:       cmd.name,
+		GuestId:    cmd.guestID,
+		NumCPUs:    int32(cmd.cpus),
+		MemoryMB:   int64(cmd.memory),
+		Annotation: cmd.annotation,
+		Firmware:   cmd.firmware,
+		Version:    cmd.version,
+		Files:      &types.VirtualMachineFileInfo{VmPathName: vmxPath},
+	}
+
+	defaults := object.DefaultDevices()
+
+	if devices, err = cmd.addStorage(defaults); err != nil {
+		return nil, err
+	}
+
+	if devices, err = cmd.addNetwork(devices); err != nil {
+		return nil, err
+	}
+
+	deviceChange, err := devices[len(defaults):].ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.DeviceChange = deviceChange
+
+	folder := object.NewFolder(cmd.Client, cmd.Client.ServiceContent.RootFolder)
+
+	return folder.CreateVM(ctx, *spec)
+}
+
+func (cmd *create) addStorage(devices object.VirtualDeviceList) (object.VirtualDeviceList, error) {
+	var controller types.BaseVirtualController
+
+	switch cmd.controller {
+	case "ide":
+		ide, err := devices.FindIDEController("")
+		if err != nil {
+			return nil, err
+		}
+
+		controller = ide
+	case "nvme":
+		nvme, err := devices.CreateNVMEController()
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, nvme)
+		controller = nvme.(types.BaseVirtualController)
+	case "sata":
+		sata, err := devices.CreateSATAController()
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, sata)
+		controller = sata.(types.BaseVirtualController)
+	default:
+		scsi, err := devices.CreateSCSIController(cmd.controller)
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, scsi)
+		controller = scsi.(types.BaseVirtualController)
+	}
+
+	if cmd.diskByteSize != 0 {
+		disk := &types.VirtualDisk{
+			VirtualDevice: types.VirtualDevice{
+				Key: devices.NewKey(),
+				Backing: &types.VirtualDiskFlatVer2BackingInfo{
+					DiskMode:        string(types.VirtualDiskModePersistent),
+					ThinProvisioned: types.NewBool(true),
+				},
+			},
+			CapacityInKB: cmd.diskByteSize / 1024,
+		}
+
+		devices.AssignController(disk, controller)
+		devices = append(devices, disk)
+	} else if cmd.disk != "" {
+		disk := devices.CreateDisk(controller, cmd.disk)
+
+		if cmd.link {
+			disk = devices.ChildDisk(disk)
+		}
+
+		devices = append(devices, disk)
+	}
+
+	if cmd.iso != "" {
+		ide, err := devices.FindIDEController("")
+		if err != nil {
+			return nil, err
+		}
+
+		cdrom, err := devices.CreateCdrom(ide)
+		if err != nil {
+			return nil, err
+		}
+
+		cdrom = devices.InsertIso(cdrom, cmd.iso)
+		devices = append(devices, cdrom)
+	}
+
+	return devices, nil
+}
+
+func (cmd *create) addNetwork(devices object.VirtualDeviceList) (object.VirtualDeviceList, error) {
+	netdev, err := cmd.NetworkFlag.Spec().EthernetCard(devices)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(devices, netdev), nil
+}
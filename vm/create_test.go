@@ -0,0 +1,165 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmdk"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestCreate(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		dir := t.TempDir()
+
+		iso := filepath.Join(dir, "boot.iso")
+		if err := os.WriteFile(iso, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := govc(t, c, "vm.create", "-dir", dir, "-on=false", "-c", "2", "-m", "2048", "-g", "ubuntu-64",
+			"-disk", "1GB", "-iso", iso, "-net", "vmnet1", "-net.adapter", "vmxnet3", "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vm := findVM(ctx, t, c, "test")
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOff)
+
+		cfg := loadVMX(ctx, t, vm)
+
+		for key, value := range map[string]string{
+			"numvcpus":                 "2",
+			"memsize":                  "2048",
+			"guestOS":                  "ubuntu-64",
+			"scsi0:0.fileName":         "test.vmdk",
+			"ethernet0.connectionType": "hostonly",
+			"ethernet0.virtualDev":     "vmxnet3",
+		} {
+			if cfg.Get(key) != value {
+				t.Errorf("%s: expected %q, got %q", key, value, cfg.Get(key))
+			}
+		}
+
+		devices, err := vm.Device(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		disk, ok := devices.Find("disk-1000-0").(*types.VirtualDisk)
+		if !ok || disk.CapacityInBytes != 1<<30 {
+			t.Errorf("unexpected disk: %v", disk)
+		}
+
+		cdrom, err := devices.FindCdrom("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if backing, ok := cdrom.Backing.(*types.VirtualCdromIsoBackingInfo); !ok || backing.FileName != iso {
+			t.Errorf("unexpected cdrom backing: %v", cdrom.Backing)
+		}
+
+		if _, err = govc(t, c, "vm.create", "-dir", dir, "-on=false", "test"); err == nil {
+			t.Error("expected an error creating an existing VM")
+		}
+
+		if _, err = govc(t, c, "vm.create", "-dir", dir, "-force", "-disk.controller", "nvme", "-disk", "2GB", "test"); err != nil {
+			t.Fatal(err)
+		}
+
+		vm = findVM(ctx, t, c, "test")
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOn)
+
+		if cfg = loadVMX(ctx, t, vm); cfg.Get("nvme0:0.fileName") != "test.vmdk" {
+			t.Errorf("unexpected vmx: %v", cfg.Entries())
+		}
+
+		// an existing disk is linked by default
+		base := filepath.Join(dir, "test", "test.vmdk")
+
+		if _, err = govc(t, c, "vm.create", "-dir", dir, "-on=false", "-disk", base, "linked"); err != nil {
+			t.Fatal(err)
+		}
+
+		if devices, err = findVM(ctx, t, c, "linked").Device(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		disk = devices.SelectByType((*types.VirtualDisk)(nil))[0].(*types.VirtualDisk)
+		if parent := disk.Backing.(*types.VirtualDiskFlatVer2BackingInfo).Parent; parent == nil || parent.FileName != base {
+			t.Errorf("unexpected disk parent: %v", parent)
+		}
+
+		if _, err = govc(t, c, "vm.create", "-dir", dir, "-disk", filepath.Join(dir, "enoent.vmdk"), "enoent"); err == nil {
+			t.Error("expected an error with a missing disk")
+		}
+	})
+}
+
+func TestCreateDiskController(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		dir := t.TempDir()
+
+		tests := []struct {
+			controller  string
+			node        string
+			virtualDev  string
+			adapterType string
+		}{
+			{"scsi", "scsi0:0", "lsilogic", "lsilogic"},
+			{"buslogic", "scsi0:0", "buslogic", "buslogic"},
+			{"lsilogic-sas", "scsi0:0", "lsisas1068", "lsilogic"},
+			{"pvscsi", "scsi0:0", "pvscsi", "lsilogic"},
+			{"sata", "sata0:0", "", "ide"},
+			{"nvme", "nvme0:0", "", "lsilogic"},
+			{"ide", "ide0:0", "", "ide"},
+		}
+
+		for _, test := range tests {
+			name := "vm-" + test.controller
+
+			if _, err := govc(t, c, "vm.create", "-dir", dir, "-on=false", "-disk.controller", test.controller, "-disk", "1GB", name); err != nil {
+				t.Fatalf("%s: %s", test.controller, err)
+			}
+
+			cfg := loadVMX(ctx, t, findVM(ctx, t, c, name))
+			if cfg.Get(test.node+".fileName") != name+".vmdk" {
+				t.Errorf("%s: unexpected vmx: %v", test.controller, cfg.Entries())
+			}
+
+			if test.virtualDev != "" && cfg.Get("scsi0.virtualDev") != test.virtualDev {
+				t.Errorf("%s: expected virtualDev %s, got %s", test.controller, test.virtualDev, cfg.Get("scsi0.virtualDev"))
+			}
+
+			d, err := vmdk.Read(filepath.Join(dir, name, name+".vmdk"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if d.AdapterType() != test.adapterType {
+				t.Errorf("%s: expected adapter type %s, got %s", test.controller, test.adapterType, d.AdapterType())
+			}
+		}
+	})
+}
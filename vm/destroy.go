@@ -21,14 +21,26 @@ package vm
 import (
 	"context"
 	"flag"
-
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
 	"github.com/vmware/govmomi/govc/cli"
-	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 type destroy struct {
+	*flags.OutputFlag
 	*flags.ClientFlag
 	*flags.SearchFlag
+
+	keepFiles bool
+	timeout   time.Duration
 }
 
 func init() {
@@ -36,17 +48,26 @@ func init() {
 }
 
 func (cmd *destroy) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
 	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
 	cmd.ClientFlag.Register(ctx, f)
 
 	cmd.SearchFlag, ctx = flags.NewSearchFlag(ctx, flags.SearchVirtualMachines)
 	cmd.SearchFlag.Register(ctx, f)
+
+	f.BoolVar(&cmd.keepFiles, "keep-files", false, "Unregister the VM without deleting its files")
+	f.DurationVar(&cmd.timeout, "shutdown", 0, "Time to wait for a guest shutdown before powering off, 0 powers off at once")
 }
 
 func (cmd *destroy) Process(ctx context.Context) error {
 	if err := cmd.ClientFlag.Process(ctx); err != nil {
 		return err
 	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
 	if err := cmd.SearchFlag.Process(ctx); err != nil {
 		return err
 	}
@@ -61,13 +82,155 @@ func (cmd *destroy) Description() string {
 	return `Power off and delete VM.
 
 When a VM is destroyed, any attached virtual disks are also deleted.
-Use the 'device.remove -vm VM -keep disk-*' command to detach and
-keep disks if needed, prior to calling vm.destroy.
+Use the 'device.disk.detach -vm VM DISK...' command to detach and
+keep disks if needed, prior to calling vm.destroy. A VM with linked
+clones cannot be destroyed, it is left untouched.
+
+With -keep-files the VM is only removed from the Workstation library,
+which must be reachable from this host. The files removed from the VM
+directory are reported when it is reachable from this host.
 
 Examples:
-  govc vm.destroy my-vm`
+  govc vm.destroy my-vm
+  govc vm.destroy -shutdown 1m my-vm
+  govc vm.destroy -keep-files my-vm`
+}
+
+// powerOff shuts the guest down if tools are running and -shutdown is set, else or on timeout powers the VM off.
+func (cmd *destroy) powerOff(ctx context.Context, vm *object.VirtualMachine) error {
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if state == types.VirtualMachinePowerStatePoweredOff {
+		return nil
+	}
+
+	if cmd.timeout > 0 && state == types.VirtualMachinePowerStatePoweredOn {
+		if err = vm.ShutdownGuest(ctx); err == nil {
+			wctx, cancel := context.WithTimeout(ctx, cmd.timeout)
+			err = vm.WaitForPowerState(wctx, types.VirtualMachinePowerStatePoweredOff)
+			cancel()
+
+			if err == nil {
+				return nil
+			}
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		if isAlreadyInState(err) {
+			return nil
+		}
+		return err
+	}
+
+	return task.Wait(ctx)
+}
+
+// files returns the files of the VM directory, if reachable from this host
+func files(ctx context.Context, vm *object.VirtualMachine) []string {
+	vmxPath, err := vm.VmxPath(ctx)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+
+	_ = filepath.WalkDir(filepath.Dir(vmxPath), func(name string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			names = append(names, name)
+		}
+		return nil
+	})
+
+	return names
 }
 
 func (cmd *destroy) Run(ctx context.Context, f *flag.FlagSet) error {
+	vms, err := cmd.VirtualMachines(f.Args())
+	if err != nil {
+		return err
+	}
+
+	// the VMs are only powered off once they can all be destroyed, the linked clones destroyed first are ignored
+	if !cmd.keepFiles {
+		destroyed := map[string]bool{}
+
+		for _, vm := range vms {
+			clones, err := vm.LinkedClones(ctx)
+			if err != nil {
+				return err
+			}
+
+			var remaining []string
+
+			for _, clone := range clones {
+				if !destroyed[clone] {
+					remaining = append(remaining, clone)
+				}
+			}
+
+			if len(remaining) != 0 {
+				return fmt.Errorf("%s: %w: %s", vm.Reference(), object.ErrLinkedClones, strings.Join(remaining, ", "))
+			}
+
+			if vmxPath, err := vm.VmxPath(ctx); err == nil {
+				destroyed[vmxPath] = true
+			}
+		}
+	}
+
+	for _, vm := range vms {
+		fmt.Fprintf(cmd, "Powering off %s... ", vm.Reference())
+
+		err = cmd.WithCancel(ctx, func(ctx context.Context) error {
+			return cmd.powerOff(ctx, vm)
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd, "OK\n")
+
+		if cmd.keepFiles {
+			fmt.Fprintf(cmd, "Unregistering %s... ", vm.Reference())
+
+			if err = vm.Unregister(ctx); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd, "OK\n")
+			continue
+		}
+
+		before := files(ctx, vm)
+
+		fmt.Fprintf(cmd, "Destroying %s... ", vm.Reference())
+
+		task, err := vm.Destroy(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err = task.Wait(ctx); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd, "OK\n")
+
+		for _, name := range before {
+			if _, err := os.Stat(name); os.IsNotExist(err) {
+				fmt.Fprintf(cmd, "  removed %s\n", name)
+			}
+		}
+	}
+
 	return nil
 }
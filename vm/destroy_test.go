@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestDestroy(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm0, err := findVM(ctx, t, c, "VM0").VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vm1, err := findVM(ctx, t, c, "VM1").VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "vm.destroy", "-shutdown", "1m", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = os.Stat(filepath.Dir(vm0)); !os.IsNotExist(err) {
+			t.Errorf("expected the VM directory to be removed: %v", err)
+		}
+
+		if _, err = govc(t, c, "vm.destroy", "-keep-files", "VM1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = os.Stat(vm1); err != nil {
+			t.Errorf("expected the vmx to be kept: %v", err)
+		}
+
+		if vms, err := find.NewFinder(c).VirtualMachineList(ctx, "*"); err == nil {
+			t.Errorf("expected no VM, got %d", len(vms))
+		}
+
+		if _, err = govc(t, c, "vm.destroy", "VM1"); err == nil {
+			t.Error("expected an error destroying an unknown VM")
+		}
+	})
+}
+
+func TestDestroyLinkedClones(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		if _, err := govc(t, c, "vm.power", "-off", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := govc(t, c, "vm.clone", "-vm.ipath", "VM0", "-link", "-on=false", "VM2"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := govc(t, c, "vm.power", "-on", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		// the parent of a linked clone is neither destroyed nor powered off
+		if _, err := govc(t, c, "vm.destroy", "VM1", "VM0"); err == nil {
+			t.Fatal("expected an error destroying the parent of a linked clone")
+		}
+
+		powerState(ctx, t, findVM(ctx, t, c, "VM0"), types.VirtualMachinePowerStatePoweredOn)
+		powerState(ctx, t, findVM(ctx, t, c, "VM1"), types.VirtualMachinePowerStatePoweredOn)
+
+		if _, err := govc(t, c, "vm.destroy", "VM2", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// removeExisting destroys the VM of the vmx path, which is refused unless force is set.
+// A vmx file left on disk without being registered is registered first, so its files are destroyed too.
+func removeExisting(ctx context.Context, c *vim25.Client, vmxPath string, force bool) error {
+	vm, err := find.NewFinder(c).VirtualMachine(ctx, object.InventoryPath(vmxPath))
+	if err != nil {
+		if _, ok := err.(*find.NotFoundError); !ok {
+			return err
+		}
+
+		if _, err = os.Stat(vmxPath); err != nil {
+			return nil
+		}
+
+		if !force {
+			return fmt.Errorf("%s already exists (use -force to destroy it)", vmxPath)
+		}
+
+		task, err := object.NewFolder(c, c.ServiceContent.RootFolder).RegisterVM(ctx, vmxPath, "")
+		if err != nil {
+			return err
+		}
+
+		info, err := task.WaitForResult(ctx)
+		if err != nil {
+			return err
+		}
+
+		vm = object.NewVirtualMachine(c, info.Result.(types.ManagedObjectReference))
+	}
+
+	if !force {
+		return fmt.Errorf("vm %q already exists (use -force to destroy it)", vm.InventoryPath)
+	}
+
+	state, err := vm.PowerState(ctx)
+	if err != nil {
+		return err
+	}
+
+	if state != types.VirtualMachinePowerStatePoweredOff {
+		task, err := vm.PowerOff(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err = task.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	task, err := vm.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+
+	return task.Wait(ctx)
+}
@@ -20,10 +20,21 @@ package vm
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/vmrest-go-client/client/model"
 	"github.com/vmware/govmomi/govc/cli"
-	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/units"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 type info struct {
@@ -78,15 +89,198 @@ func (cmd *info) Description() string {
 	return `Display info for VM.
 
 The '-r' flag displays additional info for CPU, memory and storage usage,
-along with the VM's Datastores, Networks and PortGroups.
+along with the VM's networks and shared folders. The storage usage and the
+'-e' ExtraConfig are only available when the VM files are reachable from this host.
 
 Examples:
   govc vm.info $vm
   govc vm.info -r $vm | grep Network:
   govc vm.info -json $vm
-  govc find . -type m -runtime.powerState poweredOn | xargs govc vm.info`
+  govc vm.info -e -t '*' `
 }
 
 func (cmd *info) Run(ctx context.Context, f *flag.FlagSet) error {
-	return nil
+	vms, err := cmd.VirtualMachines(f.Args())
+	if err != nil {
+		return err
+	}
+
+	res := infoResult{cmd: cmd}
+
+	for _, vm := range vms {
+		info, err := cmd.collect(ctx, vm)
+		if err != nil {
+			return err
+		}
+
+		res.VirtualMachines = append(res.VirtualMachines, *info)
+	}
+
+	return cmd.WriteResult(&res)
+}
+
+// vmInfo is the information vmrest and the vmx file provide about a VM
+type vmInfo struct {
+	Self             types.ManagedObjectReference
+	Name             string
+	Path             string
+	Uuid             string
+	GuestId          string
+	NumCpu           int
+	MemoryMB         int
+	PowerState       types.VirtualMachinePowerState
+	IpAddress        string
+	ToolsRunning     bool
+	StorageCommitted int64
+	Nics             []model.NicDevice
+	GuestNics        []model.NicIpStack
+	SharedFolders    []model.SharedFolder
+	Restrictions     *model.VmRestrictionsInformation
+	ExtraConfig      []types.BaseOptionValue
+}
+
+func (cmd *info) collect(ctx context.Context, vm *object.VirtualMachine) (*vmInfo, error) {
+	c, err := cmd.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	id := vm.Reference().Value
+
+	info := &vmInfo{
+		Self: vm.Reference(),
+		Name: vm.Name(),
+		Uuid: vm.UUID(ctx),
+	}
+
+	if info.Path, err = vm.VmxPath(ctx); err != nil {
+		return nil, err
+	}
+
+	settings, err := c.GetVM(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.Cpu != nil {
+		info.NumCpu = settings.Cpu.Processors
+	}
+	info.MemoryMB = settings.Memory
+
+	if param, err := c.GetVMParams(id, "guestOS"); err == nil {
+		info.GuestId = param.Value
+	}
+
+	if info.PowerState, err = vm.PowerState(ctx); err != nil {
+		return nil, err
+	}
+
+	// The tools are running when the guest IP address is known, as in IsToolsRunning.
+	if info.PowerState == types.VirtualMachinePowerStatePoweredOn {
+		if ip, err := c.GetIPAddress(id); err == nil {
+			info.ToolsRunning = true
+			info.IpAddress = ip.Ip
+		}
+	}
+
+	if info.ToolsRunning {
+		if stacks, err := c.GetNicInfo(id); err == nil {
+			info.GuestNics = stacks.Nics
+		}
+	}
+
+	nics, err := c.GetAllNICDevices(id)
+	if err != nil {
+		return nil, err
+	}
+	info.Nics = nics.Nics
+
+	if info.SharedFolders, err = c.GetAllSharedFolders(id); err != nil {
+		return nil, err
+	}
+
+	if info.Restrictions, err = c.GetVMRestrictions(id); err != nil {
+		return nil, err
+	}
+
+	// the vmx file and the disks are only readable from the vmrest host
+	if info.ExtraConfig, err = vm.ExtraConfig(ctx); err == nil {
+		_ = filepath.WalkDir(filepath.Dir(info.Path), func(name string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				if fi, err := d.Info(); err == nil {
+					info.StorageCommitted += fi.Size()
+				}
+			}
+			return nil
+		})
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+type infoResult struct {
+	VirtualMachines []vmInfo
+	cmd             *info
+}
+
+func (r *infoResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	for _, vm := range r.VirtualMachines {
+		fmt.Fprintf(tw, "Name:\t%s\n", vm.Name)
+
+		if r.cmd.General {
+			fmt.Fprintf(tw, "  Path:\t%s\n", vm.Path)
+			fmt.Fprintf(tw, "  UUID:\t%s\n", vm.Uuid)
+			fmt.Fprintf(tw, "  Guest name:\t%s\n", vm.GuestId)
+			fmt.Fprintf(tw, "  Memory:\t%dMB\n", vm.MemoryMB)
+			fmt.Fprintf(tw, "  CPU:\t%d vCPU(s)\n", vm.NumCpu)
+			fmt.Fprintf(tw, "  Power state:\t%s\n", vm.PowerState)
+			fmt.Fprintf(tw, "  IP address:\t%s\n", vm.IpAddress)
+		}
+
+		if r.cmd.Resources {
+			if vm.ExtraConfig != nil {
+				fmt.Fprintf(tw, "  Storage committed:\t%s\n", units.ByteSize(vm.StorageCommitted))
+			}
+
+			for _, nic := range vm.Nics {
+				fmt.Fprintf(tw, "  Network:\t%s (%s, %s)\n", nic.Vmnet, nic.Type, nic.MacAddress)
+			}
+
+			for _, folder := range vm.SharedFolders {
+				access := "read-only"
+				if folder.Flags&object.SharedFolderReadWrite != 0 {
+					access = "read/write"
+				}
+
+				fmt.Fprintf(tw, "  Shared folder:\t%s (%s, %s)\n", folder.FolderId, folder.HostPath, access)
+			}
+
+			if org := vm.Restrictions.ManagedOrg; org != "" {
+				fmt.Fprintf(tw, "  Managed by:\t%s\n", org)
+			}
+		}
+
+		if r.cmd.ExtraConfig {
+			fmt.Fprintf(tw, "  ExtraConfig:\n")
+
+			for _, v := range vm.ExtraConfig {
+				fmt.Fprintf(tw, "    %s:\t%s\n", v.GetOptionValue().Key, v.GetOptionValue().Value)
+			}
+		}
+
+		if r.cmd.ToolsConfigInfo {
+			fmt.Fprintf(tw, "  ToolsConfigInfo:\n")
+			fmt.Fprintf(tw, "    Tools running:\t%t\n", vm.ToolsRunning)
+
+			for _, nic := range vm.GuestNics {
+				fmt.Fprintf(tw, "    Guest NIC:\t%s (%s)\n", nic.Mac, strings.Join(nic.Ip, ", "))
+			}
+		}
+	}
+
+	return tw.Flush()
 }
@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestInfo(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		out, err := govc(t, c, "vm.info", "-r", "-json", "VM*")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var res struct {
+			VirtualMachines []struct {
+				Name       string
+				Path       string
+				NumCpu     int
+				MemoryMB   int
+				PowerState types.VirtualMachinePowerState
+				IpAddress  string
+				Nics       []struct{ Type string }
+			}
+		}
+
+		if err = json.Unmarshal([]byte(out), &res); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res.VirtualMachines) != 2 {
+			t.Fatalf("expected 2 VMs, got %d", len(res.VirtualMachines))
+		}
+
+		for _, vm := range res.VirtualMachines {
+			if !strings.HasSuffix(vm.Path, vm.Name+".vmx") || vm.NumCpu != 1 || vm.MemoryMB != 1024 {
+				t.Errorf("unexpected info: %+v", vm)
+			}
+
+			if vm.PowerState != types.VirtualMachinePowerStatePoweredOn || vm.IpAddress == "" {
+				t.Errorf("unexpected guest info: %+v", vm)
+			}
+
+			if len(vm.Nics) != 1 || vm.Nics[0].Type != "nat" {
+				t.Errorf("unexpected nics: %+v", vm.Nics)
+			}
+		}
+
+		if _, err = govc(t, c, "vm.change", "-e", "guestinfo.foo=bar", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		if out, err = govc(t, c, "vm.info", "-e", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, s := range []string{"Name:", "VM0", "Power state:", "poweredOn", "guestinfo.foo:", "bar"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected %q in:\n%s", s, out)
+			}
+		}
+
+		if _, err = govc(t, c, "vm.info", "enoent"); err == nil {
+			t.Error("expected an error with an unknown VM")
+		}
+	})
+}
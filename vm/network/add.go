@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govc sources https://github.com/vmware/govmomi/govc
+*/
+
+package network
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type add struct {
+	*flags.VirtualMachineFlag
+	*flags.NetworkFlag
+	*flags.OutputFlag
+}
+
+func init() {
+	cli.Register("vm.network.add", &add{})
+}
+
+func (cmd *add) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	cmd.NetworkFlag, ctx = flags.NewNetworkFlag(ctx)
+	cmd.NetworkFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+}
+
+func (cmd *add) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.NetworkFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *add) Description() string {
+	return `Add network adapter to VM.
+
+The VM must be powered off, the network defaults to nat. The adapter type and
+the static MAC address are written to the vmx file, which must be reachable from this host.
+
+Examples:
+  govc vm.network.add -vm $vm -net hostonly
+  govc vm.network.add -vm $vm -net vmnet2 -net.adapter vmxnet3
+  govc vm.network.add -vm $vm -net bridged -net.address 00:50:56:00:12:34
+  govc device.info -vm $vm ethernet-*`
+}
+
+func (cmd *add) Run(ctx context.Context, f *flag.FlagSet) error {
+	vm, err := cmd.VirtualMachineFlag.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return errors.New("please specify a vm")
+	}
+
+	nic, err := vm.AddNetworkAdapter(ctx, cmd.NetworkFlag.Spec())
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(newNicResult(nic))
+}
@@ -0,0 +1,119 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govc sources https://github.com/vmware/govmomi/govc
+*/
+
+package network
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type change struct {
+	*flags.VirtualMachineFlag
+	*flags.NetworkFlag
+	*flags.OutputFlag
+}
+
+func init() {
+	cli.Register("vm.network.change", &change{})
+}
+
+func (cmd *change) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	cmd.NetworkFlag, ctx = flags.NewNetworkFlag(ctx)
+	cmd.NetworkFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+}
+
+func (cmd *change) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.NetworkFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *change) Usage() string {
+	return "DEVICE"
+}
+
+func (cmd *change) Description() string {
+	return `Change network DEVICE configuration.
+
+DEVICE is the adapter name, as listed by device.ls, or its vmrest index starting at 1.
+The adapter type and the MAC address can only be changed while the VM is powered off
+and its vmx file is reachable from this host.
+
+Examples:
+  govc vm.network.change -vm $vm -net vmnet2 ethernet-0
+  govc vm.network.change -vm $vm -net.adapter vmxnet3 ethernet-0
+  govc vm.network.change -vm $vm -net.address 00:50:56:00:12:34 ethernet-0 # set to static MAC address
+  govc vm.network.change -vm $vm -net.address - ethernet-0 # set to generated MAC address
+  govc device.info -vm $vm ethernet-*`
+}
+
+func (cmd *change) Run(ctx context.Context, f *flag.FlagSet) error {
+	vm, err := cmd.VirtualMachineFlag.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return errors.New("please specify a vm")
+	}
+
+	name := f.Arg(0)
+
+	if name == "" {
+		return errors.New("please specify a device name")
+	}
+
+	// Set network if specified as extra argument.
+	if f.NArg() > 1 {
+		err = cmd.NetworkFlag.Set(f.Arg(1))
+		if err != nil {
+			return fmt.Errorf("couldn't set specified network %v", err)
+		}
+	}
+
+	index, err := adapterIndex(name)
+	if err != nil {
+		return err
+	}
+
+	nic, err := vm.ChangeNetworkAdapter(ctx, index, cmd.NetworkFlag.Spec())
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(newNicResult(nic))
+}
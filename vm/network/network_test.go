@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+func nic(t *testing.T, c *vim25.Client, args ...string) *nicResult {
+	t.Helper()
+
+	out, err := govc(t, c, append([]string{args[0], "-json"}, args[1:]...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res nicResult
+	if err = json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	return &res
+}
+
+func TestNetworkAdapter(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm, err := find.NewFinder(c).VirtualMachine(ctx, "VM0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vmxPath, err := vm.VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "vm.network.add", "-vm", "VM0", "-net.adapter", "vmxnet3"); err == nil {
+			t.Error("expected an error changing the adapter type of a powered on VM")
+		}
+
+		if _, err = govc(t, c, "vm.network.add", "-vm", "VM0", "-net", "hostonly"); err == nil {
+			t.Error("expected an error adding an adapter to a powered on VM")
+		}
+
+		if nics, err := vm.NetworkAdapters(ctx); err != nil || len(nics) != 1 {
+			t.Errorf("expected 1 network adapter, got %d: %v", len(nics), err)
+		}
+
+		task, err := vm.PowerOff(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = task.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		res := nic(t, c, "vm.network.add", "-vm", "VM0", "-net", "hostonly", "-net.adapter", "vmxnet3", "-net.address", "00:50:56:00:12:34")
+		if res.Name != "ethernet-1" || res.Index != 2 || res.Type != "hostonly" || res.MacAddress != "00:50:56:00:12:34" {
+			t.Errorf("unexpected nic: %+v", res)
+		}
+
+		res = nic(t, c, "vm.network.change", "-vm", "VM0", "-net", "vmnet1", "-net.address", "-", "ethernet-1")
+		if res.Type != "custom" || res.Vmnet != "vmnet1" || res.MacAddress == "00:50:56:00:12:34" {
+			t.Errorf("unexpected nic: %+v", res)
+		}
+
+		cfg, err := vmx.Load(vmxPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for key, value := range map[string]string{
+			"ethernet1.virtualDev":     "vmxnet3",
+			"ethernet1.connectionType": "custom",
+			"ethernet1.vnet":           "vmnet1",
+			"ethernet1.addressType":    "generated",
+		} {
+			if cfg.Get(key) != value {
+				t.Errorf("%s: expected %q, got %q", key, value, cfg.Get(key))
+			}
+		}
+
+		if _, err = govc(t, c, "vm.network.change", "-vm", "VM0", "-net.address", "00:0c:29:00:00:01", "ethernet-1"); err == nil {
+			t.Error("expected an error with a MAC address out of the static range")
+		}
+
+		if _, err = govc(t, c, "vm.network.remove", "-vm", "VM0", "ethernet-1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "vm.network.remove", "-vm", "VM0", "2"); err == nil {
+			t.Error("expected an error removing a missing adapter")
+		}
+
+		if _, err = govc(t, c, "vm.network.remove", "-vm", "VM0", "eth0"); err == nil {
+			t.Error("expected an error with an invalid adapter name")
+		}
+
+		nics, err := vm.NetworkAdapters(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(nics) != 1 {
+			t.Errorf("expected 1 adapter, got %d", len(nics))
+		}
+	})
+}
@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// adapterIndex returns the vmrest index of a network adapter, given as a device name
+// such as ethernet-0 or as a vmrest index starting at 1.
+func adapterIndex(name string) (int, error) {
+	if s := strings.TrimPrefix(name, "ethernet-"); s != name {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			return n + 1, nil
+		}
+	} else if n, err := strconv.Atoi(name); err == nil && n > 0 {
+		return n, nil
+	}
+
+	return 0, fmt.Errorf("invalid network adapter %q", name)
+}
+
+type nicResult struct {
+	Name       string
+	Index      int
+	Type       string
+	Vmnet      string
+	MacAddress string
+}
+
+func newNicResult(nic *model.NicDevice) *nicResult {
+	return &nicResult{
+		Name:       fmt.Sprintf("ethernet-%d", nic.Index-1),
+		Index:      nic.Index,
+		Type:       nic.Type,
+		Vmnet:      nic.Vmnet,
+		MacAddress: nic.MacAddress,
+	}
+}
+
+func (r *nicResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Name:\t%s\n", r.Name)
+	fmt.Fprintf(tw, "  Index:\t%d\n", r.Index)
+	fmt.Fprintf(tw, "  Type:\t%s\n", r.Type)
+	fmt.Fprintf(tw, "  Vmnet:\t%s\n", r.Vmnet)
+	fmt.Fprintf(tw, "  MAC Address:\t%s\n", r.MacAddress)
+
+	return tw.Flush()
+}
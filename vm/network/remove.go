@@ -0,0 +1,89 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type remove struct {
+	*flags.VirtualMachineFlag
+}
+
+func init() {
+	cli.Register("vm.network.remove", &remove{})
+}
+
+func (cmd *remove) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+}
+
+func (cmd *remove) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *remove) Usage() string {
+	return "DEVICE..."
+}
+
+func (cmd *remove) Description() string {
+	return `Remove network DEVICE from VM.
+
+DEVICE is the adapter name, as listed by device.ls, or its vmrest index starting at 1.
+The VM must be powered off.
+
+Examples:
+  govc vm.network.remove -vm $vm ethernet-1
+  govc vm.network.remove -vm $vm 2`
+}
+
+func (cmd *remove) Run(ctx context.Context, f *flag.FlagSet) error {
+	vm, err := cmd.VirtualMachineFlag.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return errors.New("please specify a vm")
+	}
+
+	if f.NArg() == 0 {
+		return flag.ErrHelp
+	}
+
+	for _, name := range f.Args() {
+		index, err := adapterIndex(name)
+		if err != nil {
+			return err
+		}
+
+		if err = vm.RemoveNetworkAdapter(ctx, index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
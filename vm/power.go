@@ -20,6 +20,7 @@ package vm
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 
@@ -108,6 +109,18 @@ func (cmd *power) Process(ctx context.Context) error {
 	return nil
 }
 
+func isToolsUnavailable(err error) bool {
+	return errors.Is(err, object.ErrToolsUnavailable)
+}
+
+func isAlreadyInState(err error) bool {
+	if e, ok := err.(*object.InvalidPowerStateError); ok {
+		return e.AlreadyInState()
+	}
+
+	return false
+}
+
 // this is annoying, but the likely use cases for Datacenter.PowerOnVM outside of this command would
 // use []types.ManagedObjectReference via ContainerView or field such as ResourcePool.Vm rather than the Finder.
 func vmReferences(vms []*object.VirtualMachine) []types.ManagedObjectReference {
@@ -157,6 +170,10 @@ func (cmd *power) Run(ctx context.Context, f *flag.FlagSet) error {
 		}
 
 		if err != nil {
+			if cmd.Force && isAlreadyInState(err) {
+				fmt.Fprintf(cmd, "OK (%s)\n", err)
+				continue
+			}
 			return err
 		}
 
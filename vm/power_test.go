@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestPower(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vm := findVM(ctx, t, c, "VM0")
+
+		tests := []struct {
+			args  []string
+			state types.VirtualMachinePowerState
+		}{
+			{[]string{"-suspend"}, types.VirtualMachinePowerStateSuspended},
+			{[]string{"-on"}, types.VirtualMachinePowerStatePoweredOn},
+			{[]string{"-reset"}, types.VirtualMachinePowerStatePoweredOn},
+			{[]string{"-r"}, types.VirtualMachinePowerStatePoweredOn},
+			{[]string{"-s"}, types.VirtualMachinePowerStatePoweredOff},
+			{[]string{"-on"}, types.VirtualMachinePowerStatePoweredOn},
+			{[]string{"-off"}, types.VirtualMachinePowerStatePoweredOff},
+		}
+
+		for _, test := range tests {
+			args := append([]string{"vm.power"}, test.args...)
+
+			if _, err := govc(t, c, append(args, "VM0")...); err != nil {
+				t.Fatalf("%v: %s", test.args, err)
+			}
+
+			if test.args[0] == "-s" {
+				if err := vm.WaitForPowerState(ctx, test.state); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			powerState(ctx, t, vm, test.state)
+		}
+
+		if _, err := govc(t, c, "vm.power", "-off", "VM0"); err == nil {
+			t.Error("expected an error powering off a powered off VM")
+		}
+
+		if _, err := govc(t, c, "vm.power", "-off", "-force", "VM0"); err != nil {
+			t.Error(err)
+		}
+
+		if _, err := govc(t, c, "vm.power", "-on", "-off", "VM0"); err == nil {
+			t.Error("expected an error with more than one power operation")
+		}
+
+		// all the VMs matching the pattern
+		if _, err := govc(t, c, "vm.power", "-on", "-force", "VM*"); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []string{"VM0", "VM1"} {
+			powerState(ctx, t, findVM(ctx, t, c, name), types.VirtualMachinePowerStatePoweredOn)
+		}
+
+		task, err := vm.Pause(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = task.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		powerState(ctx, t, vm, object.VirtualMachinePowerStatePaused)
+
+		if _, err = govc(t, c, "vm.power", "-on", "VM0"); err != nil {
+			t.Fatal(err)
+		}
+
+		powerState(ctx, t, vm, types.VirtualMachinePowerStatePoweredOn)
+	})
+}
@@ -21,11 +21,18 @@ package vm
 import (
 	"context"
 	"flag"
+	"fmt"
 
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/Fred78290/govmrest/object"
 	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 type register struct {
+	*flags.OutputFlag
+	*flags.ClientFlag
+
 	name string
 }
 
@@ -34,11 +41,22 @@ func init() {
 }
 
 func (cmd *register) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
 
 	f.StringVar(&cmd.name, "name", "", "Name of the VM")
 }
 
 func (cmd *register) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -49,12 +67,37 @@ func (cmd *register) Usage() string {
 func (cmd *register) Description() string {
 	return `Add an existing VM to the inventory.
 
-VMX is an absolute path to the vm config file.
+VMX is an absolute path to the vm config file, which must be reachable from this host.
+The VM name defaults to the displayName of the vmx file.
 
 Examples:
-  govc vm.register /path/name.vmx`
+  govc vm.register /path/name.vmx
+  govc vm.register -name my-vm /path/name.vmx`
 }
 
 func (cmd *register) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	c, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	folder := object.NewFolder(c, c.ServiceContent.RootFolder)
+
+	task, err := folder.RegisterVM(ctx, f.Arg(0), cmd.name)
+	if err != nil {
+		return err
+	}
+
+	info, err := task.WaitForResult(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd, "%s\n", info.Result.(types.ManagedObjectReference))
+
 	return nil
 }
@@ -0,0 +1,71 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Fred78290/govmrest/find"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+)
+
+func TestRegister(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vmxPath, err := findVM(ctx, t, c, "VM1").VmxPath(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "vm.unregister", "VM1"); err == nil {
+			t.Error("expected an error unregistering a powered on VM")
+		}
+
+		if _, err = govc(t, c, "vm.power", "-off", "VM1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = govc(t, c, "vm.unregister", "VM1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = find.NewFinder(c).VirtualMachine(ctx, "VM1"); err == nil {
+			t.Error("expected VM1 to be unregistered")
+		}
+
+		out, err := govc(t, c, "vm.register", vmxPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vm := findVM(ctx, t, c, "VM1")
+
+		if strings.TrimSpace(out) != vm.Reference().String() {
+			t.Errorf("expected %s, got %s", vm.Reference(), out)
+		}
+
+		if _, err = govc(t, c, "vm.register", vmxPath); err == nil {
+			t.Error("expected an error registering a VM twice")
+		}
+
+		if _, err = govc(t, c, "vm.register", vmxPath+".enoent"); err == nil {
+			t.Error("expected an error registering a missing vmx")
+		}
+	})
+}
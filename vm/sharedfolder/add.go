@@ -0,0 +1,97 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedfolder
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type add struct {
+	*flags.VirtualMachineFlag
+	*flags.OutputFlag
+
+	name     string
+	readOnly bool
+}
+
+func init() {
+	cli.Register("vm.sharedfolder.add", &add{})
+}
+
+func (cmd *add) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.name, "name", "", "Shared folder name in the guest, defaults to the directory name")
+	f.BoolVar(&cmd.readOnly, "readonly", false, "Share the folder read-only")
+}
+
+func (cmd *add) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *add) Usage() string {
+	return "PATH"
+}
+
+func (cmd *add) Description() string {
+	return `Share the host directory PATH with VM.
+
+The folder is shared read/write unless '-readonly' is specified, PATH must be
+a directory reachable from this host. Linux guests with open-vm-tools mount
+the shared folders under /mnt/hgfs.
+
+Examples:
+  govc vm.sharedfolder.add -vm $vm $HOME/src
+  govc vm.sharedfolder.add -vm $vm -name src -readonly $HOME/src`
+}
+
+func (cmd *add) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return errors.New("please specify a vm")
+	}
+
+	folders, err := vm.AddSharedFolder(ctx, cmd.name, f.Arg(0), cmd.readOnly)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&lsResult{SharedFolders: folders})
+}
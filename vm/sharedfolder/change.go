@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedfolder
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type change struct {
+	*flags.VirtualMachineFlag
+	*flags.OutputFlag
+
+	path     string
+	readOnly *bool
+}
+
+func init() {
+	cli.Register("vm.sharedfolder.change", &change{})
+}
+
+func (cmd *change) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
+	cmd.VirtualMachineFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	cmd.readOnly = nil
+
+	f.StringVar(&cmd.path, "path", "", "Host directory")
+	f.Var(flags.NewOptionalBool(&cmd.readOnly), "readonly", "Share the folder read-only")
+}
+
+func (cmd *change) Process(ctx context.Context) error {
+	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *change) Usage() string {
+	return "NAME"
+}
+
+func (cmd *change) Description() string {
+	return `Change the host directory or the access of the shared folder NAME.
+
+Examples:
+  govc vm.sharedfolder.change -vm $vm -path $HOME/src/project src
+  govc vm.sharedfolder.change -vm $vm -readonly src
+  govc vm.sharedfolder.change -vm $vm -readonly=false src`
+}
+
+func (cmd *change) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	vm, err := cmd.VirtualMachine()
+	if err != nil {
+		return err
+	}
+
+	if vm == nil {
+		return errors.New("please specify a vm")
+	}
+
+	folders, err := vm.ChangeSharedFolder(ctx, f.Arg(0), cmd.path, cmd.readOnly)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&lsResult{SharedFolders: folders})
+}
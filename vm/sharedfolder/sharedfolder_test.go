@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharedfolder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmrestsim"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+// govc runs the command against the simulator c is connected to, the command output is returned
+func govc(t *testing.T, c *vim25.Client, args ...string) (string, error) {
+	t.Helper()
+	t.Setenv("GOVMREST_URL", c.Client.(*vim25.RESTClient).URL().String())
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = w
+	rc := cli.Run(args)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	res := <-out
+	if rc != 0 {
+		return res, fmt.Errorf("%s exit code %d", args[0], rc)
+	}
+
+	return res, nil
+}
+
+func folders(t *testing.T, c *vim25.Client, args ...string) map[string]string {
+	t.Helper()
+
+	out, err := govc(t, c, append([]string{args[0], "-json"}, args[1:]...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res lsResult
+	if err = json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	// the host path of each folder, suffixed with the access
+	m := map[string]string{}
+
+	for _, folder := range res.SharedFolders {
+		access := ":ro"
+		if folder.Flags&object.SharedFolderReadWrite != 0 {
+			access = ":rw"
+		}
+
+		m[folder.FolderId] = folder.HostPath + access
+	}
+
+	return m
+}
+
+func TestSharedFolder(t *testing.T) {
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		dir := t.TempDir()
+		src, data := filepath.Join(dir, "src"), filepath.Join(dir, "data")
+
+		for _, name := range []string{src, data} {
+			if err := os.Mkdir(name, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if m := folders(t, c, "vm.sharedfolder.ls", "-vm", "VM0"); len(m) != 0 {
+			t.Errorf("expected no shared folder, got %v", m)
+		}
+
+		m := folders(t, c, "vm.sharedfolder.add", "-vm", "VM0", src)
+		if m["src"] != src+":rw" {
+			t.Errorf("unexpected shared folders: %v", m)
+		}
+
+		m = folders(t, c, "vm.sharedfolder.add", "-vm", "VM0", "-name", "share", "-readonly", data)
+		if len(m) != 2 || m["share"] != data+":ro" {
+			t.Errorf("unexpected shared folders: %v", m)
+		}
+
+		if _, err := govc(t, c, "vm.sharedfolder.add", "-vm", "VM0", src); err == nil {
+			t.Error("expected an error adding an existing shared folder")
+		}
+
+		if _, err := govc(t, c, "vm.sharedfolder.add", "-vm", "VM0", filepath.Join(dir, "enoent")); err == nil {
+			t.Error("expected an error sharing a missing directory")
+		}
+
+		m = folders(t, c, "vm.sharedfolder.change", "-vm", "VM0", "-readonly", "src")
+		if m["src"] != src+":ro" {
+			t.Errorf("unexpected shared folders: %v", m)
+		}
+
+		// the access is left unchanged
+		m = folders(t, c, "vm.sharedfolder.change", "-vm", "VM0", "-path", data, "src")
+		if m["src"] != data+":ro" {
+			t.Errorf("unexpected shared folders: %v", m)
+		}
+
+		m = folders(t, c, "vm.sharedfolder.change", "-vm", "VM0", "-readonly=false", "share")
+		if m["share"] != data+":rw" {
+			t.Errorf("unexpected shared folders: %v", m)
+		}
+
+		// the -readonly of the previous run is not applied again
+		m = folders(t, c, "vm.sharedfolder.change", "-vm", "VM0", "-path", src, "src")
+		if m["src"] != src+":ro" {
+			t.Errorf("unexpected shared folders: %v", m)
+		}
+
+		if _, err := govc(t, c, "vm.sharedfolder.remove", "-vm", "VM0", "src", "share"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := govc(t, c, "vm.sharedfolder.remove", "-vm", "VM0", "src"); err == nil {
+			t.Error("expected an error removing a missing shared folder")
+		}
+
+		if m = folders(t, c, "vm.sharedfolder.ls", "-vm", "VM0"); len(m) != 0 {
+			t.Errorf("expected no shared folder, got %v", m)
+		}
+
+		if _, err := os.Stat(src); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govc sources https://github.com/vmware/govmomi/govc
+*/
+
+package vm
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Fred78290/govmrest/flags"
+	"github.com/vmware/govmomi/govc/cli"
+)
+
+type unregister struct {
+	*flags.OutputFlag
+	*flags.ClientFlag
+	*flags.SearchFlag
+}
+
+func init() {
+	cli.Register("vm.unregister", &unregister{})
+}
+
+func (cmd *unregister) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.SearchFlag, ctx = flags.NewSearchFlag(ctx, flags.SearchVirtualMachines)
+	cmd.SearchFlag.Register(ctx, f)
+}
+
+func (cmd *unregister) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.SearchFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *unregister) Usage() string {
+	return "VM..."
+}
+
+func (cmd *unregister) Description() string {
+	return `Remove VM from the inventory without deleting its files.
+
+The VM must be powered off. vmrest has no unregistration API, the Workstation
+library file of this host (~/.vmware/inventory.vmls on Linux) is edited directly.
+Unregistering is refused unless vmrest listens on a loopback address and this file
+lists every VM reported by vmrest, so the library of another host is never edited.
+A running Workstation UI may rewrite the file with its own copy of the library.
+
+Examples:
+  govc vm.unregister my-vm
+  govc vm.register /path/my-vm.vmx # register it again`
+}
+
+func (cmd *unregister) Run(ctx context.Context, f *flag.FlagSet) error {
+	vms, err := cmd.VirtualMachines(f.Args())
+	if err != nil {
+		return err
+	}
+
+	for _, vm := range vms {
+		fmt.Fprintf(cmd, "Unregistering %s... ", vm.Reference())
+
+		if err = vm.Unregister(ctx); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd, "OK\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package vmdk creates, reads and grows the hosted VMware virtual disks: the text
+descriptor and the sparse extents of the monolithicSparse and twoGbMaxExtentSparse disks.
+*/
+package vmdk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmx"
+)
+
+// Extent is an extent line of a disk descriptor, the size is in sectors
+type Extent struct {
+	Access   string
+	Size     int64
+	Type     string
+	Filename string
+}
+
+// Descriptor is the text descriptor of a virtual disk
+type Descriptor struct {
+	CID                uint32
+	ParentCID          uint32
+	CreateType         string
+	ParentFileNameHint string
+	Extents            []Extent
+	DDB                []vmx.Entry
+}
+
+// Capacity returns the disk capacity in bytes
+func (d *Descriptor) Capacity() int64 {
+	var capacity int64
+
+	for _, e := range d.Extents {
+		capacity += e.Size
+	}
+
+	return capacity * SectorSize
+}
+
+// Get returns the value of a disk database key, an empty string if the key is not set
+func (d *Descriptor) Get(key string) string {
+	for _, e := range d.DDB {
+		if strings.EqualFold(e.Key, key) {
+			return e.Value
+		}
+	}
+
+	return ""
+}
+
+// Set sets the value of a disk database key
+func (d *Descriptor) Set(key, value string) {
+	for i := range d.DDB {
+		if strings.EqualFold(d.DDB[i].Key, key) {
+			d.DDB[i].Value = value
+			return
+		}
+	}
+
+	d.DDB = append(d.DDB, vmx.Entry{Key: key, Value: value})
+}
+
+// AdapterType returns the disk controller type recorded in the disk database
+func (d *Descriptor) AdapterType() string {
+	return d.Get("ddb.adapterType")
+}
+
+// setGeometry records the geometry of a disk of capacity sectors, IDE disks have 16 heads
+func (d *Descriptor) setGeometry(capacity int64) {
+	heads, sectors := int64(255), int64(63)
+	if d.AdapterType() == "ide" {
+		heads = 16
+	}
+
+	cylinders := capacity / (heads * sectors)
+	if cylinders > 16383 && d.AdapterType() == "ide" {
+		cylinders = 16383
+	}
+
+	d.Set("ddb.geometry.cylinders", strconv.FormatInt(cylinders, 10))
+	d.Set("ddb.geometry.heads", strconv.FormatInt(heads, 10))
+	d.Set("ddb.geometry.sectors", strconv.FormatInt(sectors, 10))
+}
+
+func (d *Descriptor) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "# Disk DescriptorFile\nversion=1\nencoding=\"UTF-8\"\n")
+	fmt.Fprintf(&b, "CID=%08x\nparentCID=%08x\n", d.CID, d.ParentCID)
+	fmt.Fprintf(&b, "createType=\"%s\"\n", d.CreateType)
+
+	if d.ParentFileNameHint != "" {
+		fmt.Fprintf(&b, "parentFileNameHint=\"%s\"\n", d.ParentFileNameHint)
+	}
+
+	fmt.Fprintf(&b, "\n# Extent description\n")
+
+	for _, e := range d.Extents {
+		fmt.Fprintf(&b, "%s %d %s \"%s\"\n", e.Access, e.Size, e.Type, e.Filename)
+	}
+
+	fmt.Fprintf(&b, "\n# The Disk Data Base\n#DDB\n\n")
+
+	for _, e := range d.DDB {
+		fmt.Fprintf(&b, "%s = \"%s\"\n", e.Key, e.Value)
+	}
+
+	return b.String()
+}
+
+// Read reads the descriptor of a virtual disk, either a text descriptor file
+// or the descriptor embedded in a monolithic sparse extent.
+func Read(name string) (*Descriptor, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var header sparseExtentHeader
+	var text []byte
+
+	err = binary.Read(f, binary.LittleEndian, &header)
+
+	switch {
+	case err == nil && header.MagicNumber == sparseMagic:
+		if header.DescriptorOffset == 0 {
+			return nil, fmt.Errorf("%s: no embedded descriptor", name)
+		}
+
+		text = make([]byte, header.DescriptorSize*SectorSize)
+		if _, err = f.ReadAt(text, int64(header.DescriptorOffset*SectorSize)); err != nil {
+			return nil, err
+		}
+
+		if i := bytes.IndexByte(text, 0); i >= 0 {
+			text = text[:i]
+		}
+	case err == nil || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF):
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		if text, err = io.ReadAll(io.LimitReader(f, descriptorSize*SectorSize)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(text, []byte("# Disk DescriptorFile")) {
+		return nil, fmt.Errorf("%s: not a virtual disk descriptor", name)
+	}
+
+	return Parse(text)
+}
+
+// Parse parses the text of a disk descriptor
+func Parse(text []byte) (*Descriptor, error) {
+	d := &Descriptor{}
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		switch fields[0] {
+		case "RW", "RDONLY", "NOACCESS":
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("invalid extent %q", line)
+			}
+
+			size, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid extent %q: %s", line, err)
+			}
+
+			d.Extents = append(d.Extents, Extent{
+				Access:   fields[0],
+				Size:     size,
+				Type:     fields[2],
+				Filename: strings.Trim(strings.Join(fields[3:], " "), `"`),
+			})
+
+			continue
+		}
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid descriptor line %q", line)
+		}
+
+		key := strings.TrimSpace(kv[0])
+		value := strings.Trim(strings.TrimSpace(kv[1]), `"`)
+
+		switch strings.ToLower(key) {
+		case "cid", "parentcid":
+			id, err := strconv.ParseUint(value, 16, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", key, value)
+			}
+
+			if strings.EqualFold(key, "cid") {
+				d.CID = uint32(id)
+			} else {
+				d.ParentCID = uint32(id)
+			}
+		case "createtype":
+			d.CreateType = value
+		case "parentfilenamehint":
+			d.ParentFileNameHint = value
+		default:
+			if strings.HasPrefix(key, "ddb.") {
+				d.DDB = append(d.DDB, vmx.Entry{Key: key, Value: value})
+			}
+		}
+	}
+
+	return d, scanner.Err()
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmdk
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Fred78290/govmrest/vmx"
+)
+
+const childDescriptor = `# Disk DescriptorFile
+version=1
+encoding="UTF-8"
+CID=1a2b3c4d
+parentCID=fffffffe
+createType="monolithicSparse"
+parentFileNameHint="../base/base disk.vmdk"
+
+# Extent description
+RW 2097152 SPARSE "child.vmdk"
+
+# The Disk Data Base
+#DDB
+
+ddb.adapterType = "lsilogic"
+ddb.geometry.cylinders = "130"
+`
+
+func TestParse(t *testing.T) {
+	d, err := Parse([]byte(childDescriptor))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := &Descriptor{
+		CID:                0x1a2b3c4d,
+		ParentCID:          0xfffffffe,
+		CreateType:         MonolithicSparse,
+		ParentFileNameHint: "../base/base disk.vmdk",
+		Extents:            []Extent{{Access: "RW", Size: 2097152, Type: "SPARSE", Filename: "child.vmdk"}},
+		DDB: []vmx.Entry{
+			{Key: "ddb.adapterType", Value: "lsilogic"},
+			{Key: "ddb.geometry.cylinders", Value: "130"},
+		},
+	}
+
+	if !reflect.DeepEqual(d, expect) {
+		t.Errorf("expected %+v, got %+v", expect, d)
+	}
+
+	if d.Capacity() != 1<<30 || d.AdapterType() != "lsilogic" || d.Get("DDB.Geometry.Cylinders") != "130" {
+		t.Errorf("unexpected descriptor: %+v", d)
+	}
+
+	// String writes a descriptor which parses to the same value
+	if d.String() != childDescriptor {
+		t.Errorf("expected:\n%s\ngot:\n%s", childDescriptor, d.String())
+	}
+
+	d.Set("ddb.adapterType", "ide")
+	d.Set("ddb.virtualHWVersion", "4")
+
+	again, err := Parse([]byte(d.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(again, d) {
+		t.Errorf("expected %+v, got %+v", d, again)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"short extent", "RW 2097152 SPARSE\n"},
+		{"extent size", "RW 2GB SPARSE \"disk.vmdk\"\n"},
+		{"cid", "CID=enoent\n"},
+		{"parent cid", "parentCID=1ffffffff\n"},
+		{"line", "ddb.adapterType\n"},
+	}
+
+	for _, test := range tests {
+		if d, err := Parse([]byte(test.text)); err == nil {
+			t.Errorf("%s: expected an error, got %+v", test.name, d)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmdk
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// SectorSize is the size of a disk sector, the unit of the descriptor extents
+	SectorSize = 512
+
+	sparseMagic      = 0x564d444b // "KDMV"
+	grainSize        = 128        // sectors, 64KB grains
+	numGTEsPerGT     = 512
+	gtSectors        = numGTEsPerGT * 4 / SectorSize
+	descriptorOffset = 1
+	descriptorSize   = 20 // sectors reserved for the embedded descriptor
+
+	flagValidNewLine = 1 << 0
+	flagRedundantGT  = 1 << 1
+	flagZeroedGTE    = 1 << 2
+	flagCompressed   = 1 << 16
+)
+
+// sparseExtentHeader is the header of a hosted sparse extent
+type sparseExtentHeader struct {
+	MagicNumber        uint32
+	Version            uint32
+	Flags              uint32
+	Capacity           uint64
+	GrainSize          uint64
+	DescriptorOffset   uint64
+	DescriptorSize     uint64
+	NumGTEsPerGT       uint32
+	RgdOffset          uint64
+	GdOffset           uint64
+	OverHead           uint64
+	UncleanShutdown    uint8
+	SingleEndLineChar  byte
+	NonEndLineChar     byte
+	DoubleEndLineChar1 byte
+	DoubleEndLineChar2 byte
+	CompressAlgorithm  uint16
+	Pad                [433]uint8
+}
+
+func sectors(n int64) int64 {
+	return (n + SectorSize - 1) / SectorSize
+}
+
+// numGTs returns the number of grain tables of an extent of capacity sectors
+func numGTs(capacity int64) int64 {
+	grains := (capacity + grainSize - 1) / grainSize
+	return (grains + numGTEsPerGT - 1) / numGTEsPerGT
+}
+
+// newSparseHeader returns the header of an empty sparse extent of capacity sectors, the space of the descriptor
+// is reserved for monolithic disks. The redundant grain directory and its tables are followed by the grain
+// directory and its tables, the grains are allocated after the metadata.
+func newSparseHeader(capacity int64, embedded bool) *sparseExtentHeader {
+	n := numGTs(capacity)
+	gdSectors := sectors(n * 4)
+
+	h := &sparseExtentHeader{
+		MagicNumber:        sparseMagic,
+		Version:            1,
+		Flags:              flagValidNewLine | flagRedundantGT,
+		Capacity:           uint64(capacity),
+		GrainSize:          grainSize,
+		NumGTEsPerGT:       numGTEsPerGT,
+		SingleEndLineChar:  '\n',
+		NonEndLineChar:     ' ',
+		DoubleEndLineChar1: '\r',
+		DoubleEndLineChar2: '\n',
+	}
+
+	rgdOffset := int64(1)
+
+	if embedded {
+		h.DescriptorOffset = descriptorOffset
+		h.DescriptorSize = descriptorSize
+		rgdOffset = descriptorOffset + descriptorSize
+	}
+
+	gdOffset := rgdOffset + gdSectors + n*gtSectors
+
+	h.RgdOffset = uint64(rgdOffset)
+	h.GdOffset = uint64(gdOffset)
+	h.OverHead = uint64(((gdOffset + gdSectors + n*gtSectors + grainSize - 1) / grainSize) * grainSize)
+
+	return h
+}
+
+// sparseMetadata returns the metadata of a sparse extent: the header, the embedded descriptor and the grain
+// directories and tables. The grain table entries are the sector offsets of the grains, nil for an empty extent.
+func sparseMetadata(h *sparseExtentHeader, descriptor string, gtes []uint32) ([]byte, error) {
+	if int64(len(descriptor)) > int64(h.DescriptorSize)*SectorSize {
+		return nil, errors.New("descriptor too large")
+	}
+
+	n := numGTs(int64(h.Capacity))
+	gdSectors := sectors(n * 4)
+	buf := bytes.NewBuffer(make([]byte, 0, h.OverHead*SectorSize))
+
+	pad := func(sector int64) {
+		buf.Write(make([]byte, sector*SectorSize-int64(buf.Len())))
+	}
+
+	if err := binary.Write(buf, binary.LittleEndian, h); err != nil {
+		return nil, err
+	}
+
+	if h.DescriptorOffset != 0 {
+		pad(int64(h.DescriptorOffset))
+		buf.WriteString(descriptor)
+	}
+
+	tables := make([]uint32, n*numGTEsPerGT)
+	copy(tables, gtes)
+
+	// the grain directories point to the grain tables that follow them
+	for _, offset := range []int64{int64(h.RgdOffset), int64(h.GdOffset)} {
+		pad(offset)
+
+		for i := int64(0); i < n; i++ {
+			if err := binary.Write(buf, binary.LittleEndian, uint32(offset+gdSectors+i*gtSectors)); err != nil {
+				return nil, err
+			}
+		}
+
+		pad(offset + gdSectors)
+
+		if err := binary.Write(buf, binary.LittleEndian, tables); err != nil {
+			return nil, err
+		}
+	}
+
+	pad(int64(h.OverHead))
+
+	return buf.Bytes(), nil
+}
+
+// writeSparseExtent writes an empty hosted sparse extent of capacity sectors,
+// the descriptor is embedded in the extent of a monolithic disk.
+func writeSparseExtent(name string, capacity int64, descriptor string) error {
+	h := newSparseHeader(capacity, descriptor != "")
+
+	b, err := sparseMetadata(h, descriptor, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	return os.WriteFile(name, b, 0644)
+}
+
+// readSparseHeader reads the header of a hosted sparse extent
+func readSparseHeader(r io.Reader) (*sparseExtentHeader, error) {
+	var h sparseExtentHeader
+
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		return nil, err
+	}
+
+	if h.MagicNumber != sparseMagic {
+		return nil, errors.New("not a sparse extent")
+	}
+
+	if h.Flags&flagCompressed != 0 || h.GrainSize != grainSize || h.NumGTEsPerGT != numGTEsPerGT {
+		return nil, fmt.Errorf("%w: stream optimized or custom grain size extent", ErrNotSupported)
+	}
+
+	return &h, nil
+}
+
+// readGrainTables returns the grain table entries of a sparse extent, the sector offset of each grain
+func readGrainTables(f io.ReaderAt, h *sparseExtentHeader) ([]uint32, error) {
+	n := numGTs(int64(h.Capacity))
+
+	gd := make([]uint32, n)
+	if err := binary.Read(io.NewSectionReader(f, int64(h.GdOffset)*SectorSize, n*4), binary.LittleEndian, gd); err != nil {
+		return nil, err
+	}
+
+	gtes := make([]uint32, n*numGTEsPerGT)
+
+	for i, offset := range gd {
+		if offset == 0 {
+			continue
+		}
+
+		r := io.NewSectionReader(f, int64(offset)*SectorSize, numGTEsPerGT*4)
+		if err := binary.Read(r, binary.LittleEndian, gtes[i*numGTEsPerGT:(i+1)*numGTEsPerGT]); err != nil {
+			return nil, err
+		}
+	}
+
+	return gtes[:(int64(h.Capacity)+grainSize-1)/grainSize], nil
+}
+
+// resizeSparseExtent rewrites a sparse extent with a capacity of capacity sectors, the allocated grains are copied
+// after the new metadata. The descriptor replaces the embedded descriptor of a monolithic disk.
+func resizeSparseExtent(name string, capacity int64, descriptor string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	h, err := readSparseHeader(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if capacity < int64(h.Capacity) {
+		return fmt.Errorf("%s: shrinking a disk is not supported", name)
+	}
+
+	gtes, err := readGrainTables(src, h)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	resized := newSparseHeader(capacity, h.DescriptorOffset != 0)
+	resized.Flags |= h.Flags & flagZeroedGTE
+	if h.Version > resized.Version {
+		resized.Version = h.Version
+	}
+
+	// the grains are copied in order, a zeroed grain entry has no grain to copy
+	allocated := make([]uint32, len(gtes))
+	next := resized.OverHead
+
+	for i, gte := range gtes {
+		if gte > 1 {
+			allocated[i] = uint32(next)
+			next += grainSize
+		} else {
+			allocated[i] = gte
+		}
+	}
+
+	metadata, err := sparseMetadata(resized, descriptor, allocated)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	tmp := name + ".tmp"
+
+	err = func() error {
+		dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		defer dst.Close()
+
+		if _, err = dst.Write(metadata); err != nil {
+			return err
+		}
+
+		grain := make([]byte, grainSize*SectorSize)
+
+		for i, gte := range gtes {
+			if gte <= 1 {
+				continue
+			}
+
+			n, err := src.ReadAt(grain, int64(gte)*SectorSize)
+			if err != nil && !(errors.Is(err, io.EOF) && n > 0) {
+				return err
+			}
+
+			// a truncated last grain reads as zeros
+			for j := n; j < len(grain); j++ {
+				grain[j] = 0
+			}
+
+			if _, err = dst.WriteAt(grain, int64(allocated[i])*SectorSize); err != nil {
+				return err
+			}
+		}
+
+		return dst.Close()
+	}()
+
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	src.Close()
+
+	return os.Rename(tmp, name)
+}
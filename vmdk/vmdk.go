@@ -0,0 +1,351 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmdk
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmx"
+)
+
+// ErrNotSupported is returned for the disk types and operations this package does not implement
+var ErrNotSupported = errors.New("not supported")
+
+// ErrHasChildren is returned when a disk that still has child disks would be extended or deleted
+var ErrHasChildren = errors.New("the disk has child disks")
+
+// The disk types that can be created
+const (
+	MonolithicSparse     = "monolithicSparse"
+	TwoGbMaxExtentSparse = "twoGbMaxExtentSparse"
+)
+
+// Types are the disk types that can be created and extended
+var Types = []string{MonolithicSparse, TwoGbMaxExtentSparse}
+
+// AdapterTypes are the disk controller types recorded in the disk database,
+// the disks of the other SCSI controllers (lsisas1068, pvscsi) and of the NVMe controllers are lsilogic disks
+var AdapterTypes = []string{"lsilogic", "buslogic", "ide"}
+
+// maxExtentSize is the size in sectors of the split extents Workstation creates, just under 4GB
+const maxExtentSize = 8323072
+
+// maxChain bounds the parent chain of a disk
+const maxChain = 32
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolve returns the path of a file referenced by the disk name, relative names are resolved from the disk directory
+func resolve(name, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+
+	return filepath.Join(filepath.Dir(name), file)
+}
+
+// extentName returns the name of the nth extent file of a split disk, "disk-s001.vmdk" is the first extent of disk.vmdk
+func extentName(name string, n int) string {
+	base := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	return fmt.Sprintf("%s-s%03d.vmdk", base, n)
+}
+
+// Create creates an empty disk of the given type, the capacity is in bytes and rounded up to a sector.
+// The adapter type is the disk controller type recorded in the disk database.
+func Create(name, diskType string, capacity int64, adapterType string) error {
+	if !contains(Types, diskType) {
+		return fmt.Errorf("%w: %q disk type, must be one of %s", ErrNotSupported, diskType, strings.Join(Types, "|"))
+	}
+
+	if !contains(AdapterTypes, adapterType) {
+		return fmt.Errorf("invalid adapter type %q, must be one of %s", adapterType, strings.Join(AdapterTypes, "|"))
+	}
+
+	if capacity <= 0 {
+		return fmt.Errorf("%s: invalid capacity %d", name, capacity)
+	}
+
+	if _, err := os.Stat(name); err == nil {
+		return fmt.Errorf("%s already exists", name)
+	}
+
+	capacity = sectors(capacity)
+
+	d := &Descriptor{
+		CID:        rand.Uint32(),
+		ParentCID:  0xffffffff,
+		CreateType: diskType,
+		DDB: []vmx.Entry{
+			{Key: "ddb.adapterType", Value: adapterType},
+		},
+	}
+
+	d.setGeometry(capacity)
+	d.Set("ddb.virtualHWVersion", "4")
+
+	if diskType == MonolithicSparse {
+		d.Extents = []Extent{{Access: "RW", Size: capacity, Type: "SPARSE", Filename: filepath.Base(name)}}
+		return writeSparseExtent(name, capacity, d.String())
+	}
+
+	if err := d.split(name, capacity); err != nil {
+		return err
+	}
+
+	return os.WriteFile(name, []byte(d.String()), 0644)
+}
+
+// split grows the extents of a twoGbMaxExtentSparse disk to the capacity in sectors, the last extent is
+// grown up to the maximum extent size and new extents are added for the rest of the capacity.
+func (d *Descriptor) split(name string, capacity int64) error {
+	remaining := capacity - d.Capacity()/SectorSize
+
+	if n := len(d.Extents); n != 0 && remaining > 0 {
+		last := &d.Extents[n-1]
+
+		if size := last.Size + remaining; last.Size < maxExtentSize {
+			if size > maxExtentSize {
+				size = maxExtentSize
+			}
+
+			if err := resizeSparseExtent(resolve(name, last.Filename), size, ""); err != nil {
+				return err
+			}
+
+			remaining -= size - last.Size
+			last.Size = size
+		}
+	}
+
+	for remaining > 0 {
+		size := remaining
+		if size > maxExtentSize {
+			size = maxExtentSize
+		}
+
+		extent := extentName(name, len(d.Extents)+1)
+
+		if err := writeSparseExtent(resolve(name, extent), size, ""); err != nil {
+			return err
+		}
+
+		d.Extents = append(d.Extents, Extent{Access: "RW", Size: size, Type: "SPARSE", Filename: extent})
+		remaining -= size
+	}
+
+	return nil
+}
+
+// CreateChild creates an empty monolithic sparse child disk of the parent disk.
+// The parent hint is written as given, relative hints are resolved from the child directory.
+func CreateChild(name, parentHint string) error {
+	parentPath := resolve(name, parentHint)
+
+	parent, err := Read(parentPath)
+	if err != nil {
+		return err
+	}
+
+	capacity := parent.Capacity() / SectorSize
+	if capacity <= 0 {
+		return fmt.Errorf("%s: invalid capacity %d", parentPath, capacity)
+	}
+
+	child := &Descriptor{
+		CID:                rand.Uint32(),
+		ParentCID:          parent.CID,
+		CreateType:         MonolithicSparse,
+		ParentFileNameHint: parentHint,
+		Extents: []Extent{
+			{Access: "RW", Size: capacity, Type: "SPARSE", Filename: filepath.Base(name)},
+		},
+	}
+
+	for _, e := range parent.DDB {
+		switch strings.ToLower(e.Key) {
+		case "ddb.uuid", "ddb.longcontentid", "ddb.deletable":
+		default:
+			child.DDB = append(child.DDB, e)
+		}
+	}
+
+	return writeSparseExtent(name, capacity, child.String())
+}
+
+// Extend grows the capacity of a disk, the capacity is in bytes and rounded up to a sector.
+// The disk must not be in use, child disks cannot be extended and neither can the disks with
+// children as the new content ID would invalidate them.
+func Extend(name string, capacity int64) error {
+	if _, err := os.Stat(name + ".lck"); err == nil {
+		return fmt.Errorf("%s is in use", name)
+	}
+
+	if err := NoChildren(name); err != nil {
+		return err
+	}
+
+	d, err := Read(name)
+	if err != nil {
+		return err
+	}
+
+	if d.ParentFileNameHint != "" {
+		return fmt.Errorf("%w: %s is a child disk", ErrNotSupported, name)
+	}
+
+	capacity = sectors(capacity)
+	current := d.Capacity() / SectorSize
+
+	if capacity < current {
+		return fmt.Errorf("%s: shrinking a disk is not supported", name)
+	}
+
+	if capacity == current {
+		return nil
+	}
+
+	d.setGeometry(capacity)
+	// the content changed for the children of the disk
+	d.CID = rand.Uint32()
+
+	switch d.CreateType {
+	case MonolithicSparse:
+		if len(d.Extents) != 1 {
+			return fmt.Errorf("%s: invalid %s extents", name, d.CreateType)
+		}
+
+		d.Extents[0].Size = capacity
+		return resizeSparseExtent(name, capacity, d.String())
+	case TwoGbMaxExtentSparse:
+		if err = d.split(name, capacity); err != nil {
+			return err
+		}
+
+		return os.WriteFile(name, []byte(d.String()), 0644)
+	default:
+		return fmt.Errorf("%w: extending a %s disk", ErrNotSupported, d.CreateType)
+	}
+}
+
+// Delete removes the descriptor and the extent files of a disk, the parent disks are kept.
+// The disks with children cannot be deleted.
+func Delete(name string) error {
+	if _, err := os.Stat(name + ".lck"); err == nil {
+		return fmt.Errorf("%s is in use", name)
+	}
+
+	if err := NoChildren(name); err != nil {
+		return err
+	}
+
+	d, err := Read(name)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range d.Extents {
+		if extent := resolve(name, e.Filename); extent != name {
+			if err = os.Remove(extent); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+
+	return os.Remove(name)
+}
+
+// Parents returns the path of the parent disks of a disk, the direct parent first
+func Parents(name string) ([]string, error) {
+	var parents []string
+
+	for len(parents) < maxChain {
+		d, err := Read(name)
+		if err != nil {
+			return parents, err
+		}
+
+		if d.ParentFileNameHint == "" {
+			return parents, nil
+		}
+
+		name = resolve(name, d.ParentFileNameHint)
+		parents = append(parents, name)
+	}
+
+	return parents, fmt.Errorf("%s: too many parent disks", name)
+}
+
+// Children returns the path of the child disks of a disk, the disks of its directory and of the sibling
+// directories are searched as the snapshots and the linked clones of a virtual machine are created there.
+func Children(name string) ([]string, error) {
+	name, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(filepath.Dir(name)), "*", "*.vmdk"))
+	if err != nil {
+		return nil, err
+	}
+
+	var children []string
+
+	for _, file := range files {
+		if file == name {
+			continue
+		}
+
+		// the extent files and the other files that are not descriptors are skipped
+		d, err := Read(file)
+		if err != nil || d.ParentFileNameHint == "" {
+			continue
+		}
+
+		if filepath.Clean(resolve(file, d.ParentFileNameHint)) == name {
+			children = append(children, file)
+		}
+	}
+
+	return children, nil
+}
+
+// NoChildren returns ErrHasChildren if the disk has child disks
+func NoChildren(name string) error {
+	children, err := Children(name)
+	if err != nil {
+		return err
+	}
+
+	if len(children) != 0 {
+		return fmt.Errorf("%w: %s is the parent of %s", ErrHasChildren, name, strings.Join(children, ", "))
+	}
+
+	return nil
+}
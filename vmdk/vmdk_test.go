@@ -0,0 +1,203 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmdk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// extentCapacities returns the capacity in sectors recorded in the header of each sparse extent of the disk
+func extentCapacities(t *testing.T, name string, d *Descriptor) []int64 {
+	t.Helper()
+
+	var res []int64
+
+	for _, e := range d.Extents {
+		f, err := os.Open(resolve(name, e.Filename))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h, err := readSparseHeader(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if int64(h.Capacity) != e.Size {
+			t.Errorf("%s: expected a capacity of %d sectors, got %d", e.Filename, e.Size, h.Capacity)
+		}
+
+		res = append(res, int64(h.Capacity))
+	}
+
+	return res
+}
+
+func TestCreateExtend(t *testing.T) {
+	tests := []struct {
+		diskType    string
+		adapterType string
+		capacity    int64
+		extents     []int64
+		extend      int64
+		extended    []int64
+	}{
+		{MonolithicSparse, "lsilogic", 1 << 30, []int64{2097152}, 5 << 30, []int64{10485760}},
+		{MonolithicSparse, "ide", 1000, []int64{2}, 1 << 20, []int64{2048}},
+		{TwoGbMaxExtentSparse, "buslogic", 1 << 30, []int64{2097152}, 3 << 30, []int64{6291456}},
+		{TwoGbMaxExtentSparse, "lsilogic", 5 << 30, []int64{maxExtentSize, 10485760 - maxExtentSize}, 9 << 30,
+			[]int64{maxExtentSize, maxExtentSize, 18874368 - 2*maxExtentSize}},
+	}
+
+	for _, test := range tests {
+		name := filepath.Join(t.TempDir(), "disk.vmdk")
+
+		if err := Create(name, test.diskType, test.capacity, test.adapterType); err != nil {
+			t.Fatalf("%s: %s", test.diskType, err)
+		}
+
+		d, err := Read(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d.CreateType != test.diskType || d.AdapterType() != test.adapterType || d.ParentCID != 0xffffffff {
+			t.Errorf("%s: unexpected descriptor %+v", test.diskType, d)
+		}
+
+		if capacities := extentCapacities(t, name, d); !equal(capacities, test.extents) {
+			t.Errorf("%s: expected extents %v, got %v", test.diskType, test.extents, capacities)
+		}
+
+		if err = Create(name, test.diskType, test.capacity, test.adapterType); err == nil {
+			t.Errorf("%s: expected an error creating an existing disk", test.diskType)
+		}
+
+		if err = Extend(name, test.capacity-SectorSize); err == nil {
+			t.Errorf("%s: expected an error shrinking the disk", test.diskType)
+		}
+
+		if err = Extend(name, test.extend); err != nil {
+			t.Fatalf("%s: %s", test.diskType, err)
+		}
+
+		extended, err := Read(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if extended.CID == d.CID || extended.AdapterType() != test.adapterType {
+			t.Errorf("%s: unexpected extended descriptor %+v", test.diskType, extended)
+		}
+
+		if capacities := extentCapacities(t, name, extended); !equal(capacities, test.extended) {
+			t.Errorf("%s: expected extents %v, got %v", test.diskType, test.extended, capacities)
+		}
+
+		if err = Delete(name); err != nil {
+			t.Fatal(err)
+		}
+
+		if entries, _ := os.ReadDir(filepath.Dir(name)); len(entries) != 0 {
+			t.Errorf("%s: expected the extents to be deleted, got %d files", test.diskType, len(entries))
+		}
+	}
+
+	name := filepath.Join(t.TempDir(), "disk.vmdk")
+
+	for _, args := range []struct {
+		diskType    string
+		adapterType string
+		capacity    int64
+	}{
+		{"monolithicFlat", "lsilogic", 1 << 30},
+		{MonolithicSparse, "pvscsi", 1 << 30},
+		{MonolithicSparse, "lsilogic", 0},
+	} {
+		if err := Create(name, args.diskType, args.capacity, args.adapterType); err == nil {
+			t.Errorf("%+v: expected an error", args)
+		}
+	}
+}
+
+func equal(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestChildren(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "vm", "disk.vmdk")
+	child := filepath.Join(dir, "clone", "disk-cl1.vmdk")
+
+	for _, d := range []string{filepath.Dir(base), filepath.Dir(child)} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Create(base, TwoGbMaxExtentSparse, 1<<20, "lsilogic"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CreateChild(child, base); err != nil {
+		t.Fatal(err)
+	}
+
+	children, err := Children(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(children) != 1 || children[0] != child {
+		t.Errorf("expected %s, got %v", child, children)
+	}
+
+	// the parent of a disk can neither be extended nor deleted
+	if err = Extend(base, 2<<20); !errors.Is(err, ErrHasChildren) {
+		t.Errorf("expected ErrHasChildren, got %v", err)
+	}
+
+	if err = Delete(base); !errors.Is(err, ErrHasChildren) {
+		t.Errorf("expected ErrHasChildren, got %v", err)
+	}
+
+	if err = Delete(child); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Extend(base, 2<<20); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Delete(base); err != nil {
+		t.Fatal(err)
+	}
+}
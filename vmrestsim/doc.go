@@ -0,0 +1,30 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package vmrestsim is a mock framework for the VMware Workstation REST API (vmrest),
+the govmrest counterpart of govmomi's vcsim.
+
+The simulator serves the vmrest API over an httptest server with in-memory power,
+network and DHCP state, the virtual machine settings are kept in real vmx files
+created in a temporary directory.
+
+	vmrestsim.Test(func(ctx context.Context, c *vim25.Client) {
+		vms, err := find.NewFinder(c).VirtualMachineList(ctx, "VM*")
+		...
+	})
+*/
+package vmrestsim
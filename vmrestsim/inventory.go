@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmrestsim
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmx"
+)
+
+var vmlistConfig = regexp.MustCompile(`(?i)^vmlist\d+\.config$`)
+
+// Inventory returns the path of the simulator virtual machine library,
+// VMs added to or removed from this file are registered or unregistered as they are with Workstation.
+func (m *Model) Inventory() string {
+	return filepath.Join(m.Dir, "inventory.vmls")
+}
+
+// writeInventory saves the registered virtual machines to the library file
+func (m *Model) writeInventory() error {
+	vmls := vmx.New()
+	vmls.Set(".encoding", "UTF-8")
+
+	for i, vm := range m.vms {
+		prefix := fmt.Sprintf("vmlist%d.", i+1)
+
+		vmls.Set(prefix+"config", vm.Path)
+		vmls.Set(prefix+"DisplayName", strings.TrimSuffix(filepath.Base(vm.Path), filepath.Ext(vm.Path)))
+		vmls.Set(prefix+"ParentID", "0")
+		vmls.Set(prefix+"ItemID", fmt.Sprintf("%d", i+1))
+		vmls.Set(prefix+"State", "normal")
+	}
+
+	return vmls.Save(m.Inventory())
+}
+
+// readInventory synchronizes the registered virtual machines with the library file
+func (m *Model) readInventory() error {
+	vmls, err := vmx.Load(m.Inventory())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	listed := map[string]bool{}
+
+	for _, e := range vmls.Entries() {
+		if vmlistConfig.MatchString(e.Key) {
+			listed[e.Value] = true
+		}
+	}
+
+	vms := m.vms[:0]
+
+	for _, vm := range m.vms {
+		if listed[vm.Path] {
+			vms = append(vms, vm)
+			delete(listed, vm.Path)
+		}
+	}
+
+	m.vms = vms
+
+	for vmxPath := range listed {
+		if _, err := m.register(vmxPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+Based on govmomi simulator https://github.com/vmware/govmomi/simulator
+*/
+
+package vmrestsim
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fred78290/govmrest/object"
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/Fred78290/vmrest-go-client/client"
+)
+
+// Model is used to populate a simulator with an initial set of virtual machines.
+// The virtual machine files are created on the local file system, the vmx file
+// is the source of truth for the settings as it is for vmrest.
+type Model struct {
+	// Autostart will power on Model created VMs when true
+	Autostart bool
+
+	// Machine specifies the number of VirtualMachine entities to create
+	// Name prefix: VM
+	Machine int
+
+	// Dir is the directory where the virtual machines are created,
+	// a temporary directory is used when empty.
+	Dir string
+
+	// Username and Password enable basic authentication when set
+	Username string
+	Password string
+
+	mu     sync.Mutex
+	vms    []*VirtualMachine
+	vmnets []*Vmnet
+	leases map[string]string
+	dirs   []string
+}
+
+// Workstation is the default Model, 2 running virtual machines.
+func Workstation() *Model {
+	return &Model{
+		Autostart: true,
+		Machine:   2,
+	}
+}
+
+// Create populates the Model with the default vmnets and the given number of virtual machines.
+func (m *Model) Create() error {
+	if m.Dir == "" {
+		dir, err := os.MkdirTemp("", "vmrestsim-")
+		if err != nil {
+			return err
+		}
+
+		m.Dir = dir
+		m.dirs = append(m.dirs, dir)
+	}
+
+	m.vmnets = defaultVmnets()
+	m.leases = map[string]string{}
+
+	for i := 0; i < m.Machine; i++ {
+		vm, err := m.CreateVM(fmt.Sprintf("VM%d", i))
+		if err != nil {
+			return err
+		}
+
+		if m.Autostart {
+			if _, err := m.power(vm, powerOn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Remove cleans up the directories created by the Model
+func (m *Model) Remove() {
+	for _, dir := range m.dirs {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// CreateVM creates the files of a powered off virtual machine in the Model directory and registers it.
+func (m *Model) CreateVM(name string) (*VirtualMachine, error) {
+	dir := filepath.Join(m.Dir, name)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	vmxPath := filepath.Join(dir, name+".vmx")
+	disk := name + ".vmdk"
+
+	cfg := vmx.New()
+	cfg.Set(".encoding", "UTF-8")
+	cfg.Set("config.version", "8")
+	cfg.Set("virtualHW.version", "19")
+	cfg.Set("displayName", name)
+	cfg.Set("guestOS", "ubuntu-64")
+	cfg.Set("memsize", "1024")
+	cfg.Set("numvcpus", "1")
+	cfg.Set("uuid.bios", biosUUID(vmxPath))
+	cfg.Set("scsi0.present", "TRUE")
+	cfg.Set("scsi0.virtualDev", "lsilogic")
+	cfg.Set("scsi0:0.present", "TRUE")
+	cfg.Set("scsi0:0.fileName", disk)
+	cfg.Set("ethernet0.present", "TRUE")
+	cfg.Set("ethernet0.connectionType", "nat")
+	cfg.Set("ethernet0.virtualDev", "e1000")
+	cfg.Set("ethernet0.addressType", "generated")
+	cfg.Set("ethernet0.generatedAddress", macAddress(vmxPath, 0))
+
+	if err := cfg.Save(vmxPath); err != nil {
+		return nil, err
+	}
+
+	descriptor := fmt.Sprintf(vmdkDescriptor, name)
+	if err := os.WriteFile(filepath.Join(dir, disk), []byte(descriptor), 0644); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.register(vmxPath)
+}
+
+const vmdkDescriptor = `# Disk DescriptorFile
+version=1
+CID=fffffffe
+parentCID=ffffffff
+createType="twoGbMaxExtentSparse"
+
+# Extent description
+RW 33554432 SPARSE "%s-s001.vmdk"
+
+# The Disk Data Base
+#DDB
+
+ddb.adapterType = "lsilogic"
+ddb.virtualHWVersion = "19"
+`
+
+// VirtualMachines returns the registered virtual machines
+func (m *Model) VirtualMachines() []*VirtualMachine {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*VirtualMachine(nil), m.vms...)
+}
+
+// Vmnets returns the virtual networks
+func (m *Model) Vmnets() []*Vmnet {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Vmnet(nil), m.vmnets...)
+}
+
+func (m *Model) register(vmxPath string) (*VirtualMachine, error) {
+	for _, vm := range m.vms {
+		if vm.Path == vmxPath {
+			return nil, newFault(409, "The virtual machine is already registered: %s", vmxPath)
+		}
+	}
+
+	if err := generateAddresses(vmxPath); err != nil {
+		return nil, err
+	}
+
+	vm := &VirtualMachine{
+		ID:           vmID(vmxPath),
+		Path:         vmxPath,
+		PowerState:   poweredOff,
+		ToolsRunning: true,
+	}
+
+	m.vms = append(m.vms, vm)
+
+	sort.Slice(m.vms, func(i, j int) bool {
+		return m.vms[i].Path < m.vms[j].Path
+	})
+
+	return vm, m.writeInventory()
+}
+
+// generateAddresses fills the missing generated MAC addresses, as Workstation does for a copied VM
+func generateAddresses(vmxPath string) error {
+	cfg, err := vmx.Load(vmxPath)
+	if err != nil {
+		return err
+	}
+
+	update := false
+
+	for i := 0; i < maxNIC; i++ {
+		prefix := fmt.Sprintf("ethernet%d.", i)
+
+		if strings.EqualFold(cfg.Get(prefix+"addressType"), "generated") && cfg.Get(prefix+"generatedAddress") == "" {
+			cfg.Set(prefix+"generatedAddress", macAddress(vmxPath, i))
+			update = true
+		}
+	}
+
+	if !update {
+		return nil
+	}
+
+	return cfg.Save(vmxPath)
+}
+
+func (m *Model) vm(id string) *VirtualMachine {
+	for _, vm := range m.vms {
+		if vm.ID == id {
+			return vm
+		}
+	}
+
+	return nil
+}
+
+// vmID derives the vmrest ID of a virtual machine from its vmx path
+func vmID(vmxPath string) string {
+	return strings.ToUpper(fmt.Sprintf("%x", md5.Sum([]byte(vmxPath))))
+}
+
+func biosUUID(vmxPath string) string {
+	sum := md5.Sum([]byte("uuid:" + vmxPath))
+	var parts []string
+
+	for _, b := range sum {
+		parts = append(parts, fmt.Sprintf("%02x", b))
+	}
+
+	return strings.Join(parts[:8], " ") + "-" + strings.Join(parts[8:], " ")
+}
+
+func macAddress(vmxPath string, index int) string {
+	sum := md5.Sum([]byte(fmt.Sprintf("mac:%s:%d", vmxPath, index)))
+
+	return fmt.Sprintf("00:0c:29:%02x:%02x:%02x", sum[0], sum[1], sum[2])
+}
+
+// Run calls f with a Client connected to a simulator server instance, which is stopped after f returns.
+func (m *Model) Run(f func(context.Context, *vim25.Client) error) error {
+	ctx := context.Background()
+
+	defer m.Remove()
+
+	if m.vmnets == nil {
+		if err := m.Create(); err != nil {
+			return err
+		}
+	}
+
+	s := m.NewServer()
+	defer s.Close()
+
+	// VirtualMachine.Unregister edits the simulator library
+	inventory := object.InventoryFile
+	object.InventoryFile = m.Inventory
+	defer func() { object.InventoryFile = inventory }()
+
+	c, err := vim25.NewClient(ctx, &client.APIClient{Client: vim25.NewRESTClient(s.URL, time.Minute)})
+	if err != nil {
+		return err
+	}
+
+	return f(ctx, c)
+}
+
+// Run calls Model.Run for each model and will panic if f returns an error.
+// If no model is specified, the Workstation Model is used by default.
+func Run(f func(context.Context, *vim25.Client) error, model ...*Model) {
+	m := model
+	if len(m) == 0 {
+		m = []*Model{Workstation()}
+	}
+
+	for i := range m {
+		err := m[i].Run(f)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Test calls Run and expects the caller propagate any errors, via testing.T for example.
+func Test(f func(context.Context, *vim25.Client), model ...*Model) {
+	Run(func(ctx context.Context, c *vim25.Client) error {
+		f(ctx, c)
+		return nil
+	}, model...)
+}
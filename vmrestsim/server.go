@@ -0,0 +1,163 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmrestsim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vim25"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// Server provides a simulator http server instance
+type Server struct {
+	*httptest.Server
+
+	URL *url.URL
+}
+
+// NewServer returns a Server instance serving the Model vmrest API.
+// The Server URL embeds the Model credentials when set.
+func (m *Model) NewServer() *Server {
+	s := &Server{
+		Server: httptest.NewServer(m),
+	}
+
+	s.URL, _ = url.Parse(s.Server.URL)
+
+	if m.Username != "" {
+		s.URL.User = url.UserPassword(m.Username, m.Password)
+	}
+
+	return s
+}
+
+func newFault(status int, format string, args ...interface{}) *vim25.Fault {
+	return &vim25.Fault{
+		StatusCode: status,
+		ErrorModel: model.ErrorModel{
+			Code:    status,
+			Message: fmt.Sprintf(format, args...),
+		},
+	}
+}
+
+func notFound(kind, name string) *vim25.Fault {
+	return newFault(http.StatusNotFound, "The %s is not found: %s", kind, name)
+}
+
+func methodNotAllowed(r *http.Request) *vim25.Fault {
+	return newFault(http.StatusMethodNotAllowed, "%s %s is not supported", r.Method, r.URL.Path)
+}
+
+var success = &model.ErrorModel{Code: 0, Message: "The operation was successful"}
+
+// decode reads the JSON request body into v
+func decode(r *http.Request, v interface{}) error {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(b, v); err != nil {
+		return newFault(http.StatusBadRequest, "Invalid request body: %s", err)
+	}
+
+	return nil
+}
+
+func (m *Model) authorized(r *http.Request) bool {
+	if m.Username == "" {
+		return true
+	}
+
+	username, password, ok := r.BasicAuth()
+
+	return ok && username == m.Username && password == m.Password
+}
+
+// ServeHTTP implements the vmrest API
+func (m *Model) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var res interface{}
+	var err error
+
+	if !m.authorized(r) {
+		err = newFault(http.StatusUnauthorized, "Authentication failed")
+	} else {
+		var parts []string
+
+		for _, p := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+			if p, err = url.PathUnescape(p); err != nil {
+				break
+			}
+			parts = append(parts, p)
+		}
+
+		if err == nil {
+			m.mu.Lock()
+			if err = m.readInventory(); err == nil {
+				res, err = m.route(r, parts)
+			}
+			m.mu.Unlock()
+		}
+	}
+
+	w.Header().Set("Content-Type", vim25.MediaType)
+
+	if err != nil {
+		fault, ok := err.(*vim25.Fault)
+		if !ok {
+			fault = newFault(http.StatusInternalServerError, "%s", err)
+		}
+
+		w.WriteHeader(fault.StatusCode)
+		_ = json.NewEncoder(w).Encode(fault.ErrorModel)
+		return
+	}
+
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (m *Model) route(r *http.Request, parts []string) (interface{}, error) {
+	if len(parts) == 1 && parts[0] == "" {
+		return map[string]string{"name": "vmrestsim"}, nil
+	}
+
+	if parts[0] != "api" || len(parts) < 2 {
+		return nil, newFault(http.StatusNotFound, "Not found: %s", r.URL.Path)
+	}
+
+	switch parts[1] {
+	case "vms":
+		return m.serveVMs(r, parts[2:])
+	case "vmnet", "vmnets":
+		return m.serveVmnets(r, parts[1:])
+	}
+
+	return nil, newFault(http.StatusNotFound, "Not found: %s", r.URL.Path)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmrestsim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// sharedFolderReadWrite is the only flag supported by vmrest
+const sharedFolderReadWrite = 4
+
+const maxSharedFolder = 64
+
+// sharedFolders returns the shared folders defined in the vmx with their index
+func sharedFolders(vmx *vmx.File) (model.SharedFolders, []int) {
+	folders := model.SharedFolders{}
+	var indexes []int
+
+	for i := 0; i < maxSharedFolder; i++ {
+		prefix := fmt.Sprintf("sharedFolder%d.", i)
+
+		if !strings.EqualFold(vmx.Get(prefix+"present"), "TRUE") {
+			continue
+		}
+
+		folder := model.SharedFolder{
+			FolderId: vmx.Get(prefix + "guestName"),
+			HostPath: vmx.Get(prefix + "hostPath"),
+		}
+
+		if strings.EqualFold(vmx.Get(prefix+"writeAccess"), "TRUE") {
+			folder.Flags = sharedFolderReadWrite
+		}
+
+		folders = append(folders, folder)
+		indexes = append(indexes, i)
+	}
+
+	return folders, indexes
+}
+
+func setSharedFolder(vmx *vmx.File, i int, folder model.SharedFolder) {
+	prefix := fmt.Sprintf("sharedFolder%d.", i)
+
+	vmx.Set(prefix+"present", "TRUE")
+	vmx.Set(prefix+"enabled", "TRUE")
+	vmx.Set(prefix+"readAccess", "TRUE")
+	vmx.Set(prefix+"writeAccess", strings.ToUpper(strconv.FormatBool(folder.Flags&sharedFolderReadWrite != 0)))
+	vmx.Set(prefix+"hostPath", folder.HostPath)
+	vmx.Set(prefix+"guestName", folder.FolderId)
+	vmx.Set(prefix+"expiration", "never")
+}
+
+func (m *Model) serveSharedFolders(r *http.Request, vm *VirtualMachine, vmx *vmx.File, parts []string) (interface{}, error) {
+	folders, indexes := sharedFolders(vmx)
+
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			return folders, nil
+		case http.MethodPost:
+			var param model.SharedFolder
+
+			if err := decode(r, &param); err != nil {
+				return nil, err
+			}
+
+			if param.FolderId == "" || param.HostPath == "" {
+				return nil, newFault(http.StatusBadRequest, "The folder id and host path are required")
+			}
+
+			for _, folder := range folders {
+				if folder.FolderId == param.FolderId {
+					return nil, newFault(http.StatusConflict, "The shared folder already exists: %s", param.FolderId)
+				}
+			}
+
+			i := 0
+			for _, index := range indexes {
+				if index >= i {
+					i = index + 1
+				}
+			}
+
+			setSharedFolder(vmx, i, param)
+			vmx.Set("sharedFolder.maxNum", strconv.Itoa(i+1))
+			vmx.Set("isolation.tools.hgfs.disable", "FALSE")
+
+			if err := vmx.Save(vm.Path); err != nil {
+				return nil, err
+			}
+
+			folders, _ = sharedFolders(vmx)
+
+			return folders, nil
+		}
+
+		return nil, methodNotAllowed(r)
+	}
+
+	i := -1
+	for n, folder := range folders {
+		if folder.FolderId == parts[0] {
+			i = indexes[n]
+		}
+	}
+
+	if i < 0 {
+		return nil, notFound("shared folder", parts[0])
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var param model.SharedFolderParameter
+
+		if err := decode(r, &param); err != nil {
+			return nil, err
+		}
+
+		if param.HostPath == "" {
+			return nil, newFault(http.StatusBadRequest, "The host path is required")
+		}
+
+		setSharedFolder(vmx, i, model.SharedFolder{FolderId: parts[0], HostPath: param.HostPath, Flags: param.Flags})
+	case http.MethodDelete:
+		vmx.RemovePrefix(fmt.Sprintf("sharedFolder%d.", i))
+	default:
+		return nil, methodNotAllowed(r)
+	}
+
+	if err := vmx.Save(vm.Path); err != nil {
+		return nil, err
+	}
+
+	if r.Method == http.MethodDelete {
+		return nil, nil
+	}
+
+	folders, _ = sharedFolders(vmx)
+
+	return folders, nil
+}
@@ -0,0 +1,608 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmrestsim
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/Fred78290/govmrest/vmx"
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// vmrest power states
+const (
+	poweredOn  = "poweredOn"
+	poweredOff = "poweredOff"
+	suspended  = "suspended"
+	paused     = "paused"
+)
+
+// vmrest power operations
+const (
+	powerOn  = "on"
+	powerOff = "off"
+	shutdown = "shutdown"
+	suspend  = "suspend"
+	pause    = "pause"
+	unpause  = "unpause"
+)
+
+const maxNIC = 10
+
+// VirtualMachine is a simulated virtual machine, the settings are read from the vmx file on each request.
+type VirtualMachine struct {
+	ID         string
+	Path       string
+	PowerState string
+
+	// ToolsRunning simulates VMware Tools running in the guest,
+	// required to report the guest IP address and to shutdown the guest.
+	ToolsRunning bool
+
+	ip string
+}
+
+func (vm *VirtualMachine) vmx() (*vmx.File, error) {
+	return vmx.Load(vm.Path)
+}
+
+func (vm *VirtualMachine) information(vmx *vmx.File) *model.VmInformation {
+	cpus, _ := strconv.Atoi(vmx.Get("numvcpus"))
+	memory, _ := strconv.Atoi(vmx.Get("memsize"))
+
+	if cpus == 0 {
+		cpus = 1
+	}
+
+	return &model.VmInformation{
+		Id:     vm.ID,
+		Cpu:    &model.Vmcpu{Processors: cpus},
+		Memory: memory,
+	}
+}
+
+func (vm *VirtualMachine) checkPoweredOff() error {
+	if vm.PowerState == poweredOn || vm.PowerState == paused {
+		return newFault(http.StatusConflict, "The operation is not allowed while the virtual machine is powered on")
+	}
+
+	return nil
+}
+
+// nics returns the network adapters defined in the vmx, vmrest indexes start at 1
+func nics(vmx *vmx.File) []model.NicDevice {
+	var devices []model.NicDevice
+
+	for i := 0; i < maxNIC; i++ {
+		prefix := fmt.Sprintf("ethernet%d.", i)
+
+		if !strings.EqualFold(vmx.Get(prefix+"present"), "TRUE") {
+			continue
+		}
+
+		nic := model.NicDevice{
+			Index:      i + 1,
+			Type:       strings.ToLower(vmx.Get(prefix + "connectionType")),
+			MacAddress: vmx.Get(prefix + "address"),
+		}
+
+		if nic.MacAddress == "" {
+			nic.MacAddress = vmx.Get(prefix + "generatedAddress")
+		}
+
+		switch nic.Type {
+		case "", "bridged":
+			nic.Type = "bridged"
+			nic.Vmnet = "vmnet0"
+		case "hostonly":
+			nic.Vmnet = "vmnet1"
+		case "nat":
+			nic.Vmnet = "vmnet8"
+		default:
+			nic.Vmnet = vmx.Get(prefix + "vnet")
+		}
+
+		devices = append(devices, nic)
+	}
+
+	return devices
+}
+
+func (m *Model) serveVMs(r *http.Request, parts []string) (interface{}, error) {
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			vmids := []model.Vmid{}
+			for _, vm := range m.vms {
+				vmids = append(vmids, model.Vmid{Id: vm.ID, Path: vm.Path})
+			}
+			return vmids, nil
+		case http.MethodPost:
+			return m.cloneVM(r)
+		}
+
+		return nil, methodNotAllowed(r)
+	}
+
+	if parts[0] == "registration" && len(parts) == 1 {
+		if r.Method != http.MethodPost {
+			return nil, methodNotAllowed(r)
+		}
+
+		return m.registerVM(r)
+	}
+
+	vm := m.vm(parts[0])
+	if vm == nil {
+		return nil, notFound("virtual machine", parts[0])
+	}
+
+	vmx, err := vm.vmx()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			return vm.information(vmx), nil
+		case http.MethodPut:
+			return m.updateVM(r, vm, vmx)
+		case http.MethodDelete:
+			return nil, m.deleteVM(vm)
+		}
+
+		return nil, methodNotAllowed(r)
+	}
+
+	switch parts[1] {
+	case "power":
+		switch r.Method {
+		case http.MethodGet:
+			return &model.VmPowerState{PowerState: vm.PowerState}, nil
+		case http.MethodPut:
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+			return m.power(vm, strings.Trim(strings.TrimSpace(string(b)), `"`))
+		}
+	case "ip":
+		if r.Method == http.MethodGet {
+			return m.guestIP(vm)
+		}
+	case "nicips":
+		if r.Method == http.MethodGet {
+			return m.nicIPs(vm, vmx), nil
+		}
+	case "nic":
+		return m.serveNIC(r, vm, vmx, parts[2:])
+	case "sharedfolders":
+		return m.serveSharedFolders(r, vm, vmx, parts[2:])
+	case "params", "configparams":
+		switch {
+		case r.Method == http.MethodGet && len(parts) == 3:
+			return &model.ConfigVmParamsParameter{Name: parts[2], Value: vmx.Get(parts[2])}, nil
+		case r.Method == http.MethodPut && len(parts) == 2:
+			var param model.ConfigVmParamsParameter
+			if err := decode(r, &param); err != nil {
+				return nil, err
+			}
+			if param.Name == "" {
+				return nil, newFault(http.StatusBadRequest, "The parameter name is required")
+			}
+			vmx.Set(param.Name, param.Value)
+			if err := vmx.Save(vm.Path); err != nil {
+				return nil, err
+			}
+			return success, nil
+		}
+	case "restrictions":
+		if r.Method == http.MethodGet {
+			info := vm.information(vmx)
+			devices := nics(vmx)
+			return &model.VmRestrictionsInformation{
+				Id:      vm.ID,
+				Cpu:     info.Cpu,
+				Memory:  info.Memory,
+				Niclist: &model.NicDevices{Num: len(devices), Nics: devices},
+				GuestIsolation: &model.VmGuestIsolation{
+					HgfsDisabled: strings.ToLower(vmx.Get("isolation.tools.hgfs.disable")),
+				},
+			}, nil
+		}
+	default:
+		return nil, newFault(http.StatusNotFound, "Not found: %s", r.URL.Path)
+	}
+
+	return nil, methodNotAllowed(r)
+}
+
+func (m *Model) updateVM(r *http.Request, vm *VirtualMachine, vmx *vmx.File) (interface{}, error) {
+	var param model.VmParameter
+
+	if err := decode(r, &param); err != nil {
+		return nil, err
+	}
+
+	if err := vm.checkPoweredOff(); err != nil {
+		return nil, err
+	}
+
+	if param.Processors < 0 || param.Memory < 0 || param.Memory%4 != 0 {
+		return nil, newFault(http.StatusBadRequest, "Invalid parameters: processors=%d memory=%d", param.Processors, param.Memory)
+	}
+
+	if param.Processors > 0 {
+		vmx.Set("numvcpus", strconv.Itoa(param.Processors))
+	}
+
+	if param.Memory > 0 {
+		vmx.Set("memsize", strconv.Itoa(param.Memory))
+	}
+
+	if err := vmx.Save(vm.Path); err != nil {
+		return nil, err
+	}
+
+	return vm.information(vmx), nil
+}
+
+func (m *Model) deleteVM(vm *VirtualMachine) error {
+	if vm.PowerState != poweredOff {
+		return newFault(http.StatusConflict, "The virtual machine must be powered off to be deleted")
+	}
+
+	for i, v := range m.vms {
+		if v == vm {
+			m.vms = append(m.vms[:i], m.vms[i+1:]...)
+			break
+		}
+	}
+
+	if err := m.writeInventory(); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(filepath.Dir(vm.Path))
+}
+
+func (m *Model) registerVM(r *http.Request) (interface{}, error) {
+	var param model.VmRegisterParameter
+
+	if err := decode(r, &param); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(param.Path); err != nil {
+		return nil, newFault(http.StatusBadRequest, "The file is not found: %s", param.Path)
+	}
+
+	if _, err := vmx.Load(param.Path); err != nil {
+		return nil, newFault(http.StatusBadRequest, "The file is not a valid vmx file: %s", err)
+	}
+
+	vm, err := m.register(param.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.VmRegistrationInformation{Id: vm.ID, Path: vm.Path}, nil
+}
+
+// cloneVM copies the parent directory next to it, files prefixed by the parent name are renamed.
+func (m *Model) cloneVM(r *http.Request) (interface{}, error) {
+	var param model.VmCloneParameter
+
+	if err := decode(r, &param); err != nil {
+		return nil, err
+	}
+
+	parent := m.vm(param.ParentId)
+	if parent == nil {
+		return nil, notFound("virtual machine", param.ParentId)
+	}
+
+	if param.Name == "" || strings.ContainsAny(param.Name, `/\`) {
+		return nil, newFault(http.StatusBadRequest, "Invalid virtual machine name: %q", param.Name)
+	}
+
+	srcDir := filepath.Dir(parent.Path)
+	dstDir := filepath.Join(filepath.Dir(srcDir), param.Name)
+	prefix := strings.TrimSuffix(filepath.Base(parent.Path), filepath.Ext(parent.Path))
+
+	if _, err := os.Stat(dstDir); err == nil {
+		return nil, newFault(http.StatusConflict, "The destination already exists: %s", dstDir)
+	}
+
+	rename := func(name string) string {
+		if strings.HasPrefix(name, prefix) {
+			return param.Name + strings.TrimPrefix(name, prefix)
+		}
+		return name
+	}
+
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || name == filepath.Base(parent.Path) || strings.HasSuffix(name, ".log") {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(srcDir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(filepath.Join(dstDir, rename(name)), b, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	vmx, err := parent.vmx()
+	if err != nil {
+		return nil, err
+	}
+
+	vmxPath := filepath.Join(dstDir, param.Name+".vmx")
+
+	for _, e := range vmx.Entries() {
+		if strings.HasSuffix(strings.ToLower(e.Key), ".filename") && !filepath.IsAbs(e.Value) {
+			vmx.Set(e.Key, rename(e.Value))
+		}
+	}
+
+	vmx.Set("displayName", param.Name)
+	vmx.Set("uuid.bios", biosUUID(vmxPath))
+	vmx.Remove("uuid.location")
+
+	for i := 0; i < maxNIC; i++ {
+		key := fmt.Sprintf("ethernet%d.generatedAddress", i)
+		if vmx.Has(key) {
+			vmx.Set(key, macAddress(vmxPath, i))
+		}
+	}
+
+	if err := vmx.Save(vmxPath); err != nil {
+		return nil, err
+	}
+
+	vm, err := m.register(vmxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return vm.information(vmx), nil
+}
+
+// power applies a vmrest power operation
+func (m *Model) power(vm *VirtualMachine, op string) (*model.VmPowerState, error) {
+	invalid := func() error {
+		return newFault(http.StatusConflict, "The operation %q is not allowed in the power state %q", op, vm.PowerState)
+	}
+
+	switch op {
+	case powerOn:
+		if vm.PowerState != poweredOff && vm.PowerState != suspended {
+			return nil, invalid()
+		}
+		vm.PowerState = poweredOn
+		m.assignIP(vm)
+	case powerOff:
+		if vm.PowerState == poweredOff {
+			return nil, invalid()
+		}
+		vm.PowerState = poweredOff
+		vm.ip = ""
+	case shutdown:
+		if vm.PowerState != poweredOn {
+			return nil, invalid()
+		}
+		if !vm.ToolsRunning {
+			return nil, newFault(http.StatusInternalServerError, "VMware Tools is not running in the guest")
+		}
+		vm.PowerState = poweredOff
+		vm.ip = ""
+	case suspend:
+		if vm.PowerState != poweredOn {
+			return nil, invalid()
+		}
+		vm.PowerState = suspended
+	case pause:
+		if vm.PowerState != poweredOn {
+			return nil, invalid()
+		}
+		vm.PowerState = paused
+	case unpause:
+		if vm.PowerState != paused {
+			return nil, invalid()
+		}
+		vm.PowerState = poweredOn
+	default:
+		return nil, newFault(http.StatusBadRequest, "Invalid power operation: %q", op)
+	}
+
+	return &model.VmPowerState{PowerState: vm.PowerState}, nil
+}
+
+// assignIP gives the primary NIC an address, the DHCP reservation if any or the next free lease.
+func (m *Model) assignIP(vm *VirtualMachine) {
+	vmx, err := vm.vmx()
+	if err != nil {
+		return
+	}
+
+	devices := nics(vmx)
+	if len(devices) == 0 {
+		return
+	}
+
+	nic := devices[0]
+	mac := strings.ToLower(nic.MacAddress)
+
+	vmnet := m.vmnet(nic.Vmnet)
+	if vmnet == nil {
+		return
+	}
+
+	if ip, ok := vmnet.mactoips[mac]; ok {
+		vm.ip = ip
+		return
+	}
+
+	if ip, ok := m.leases[mac]; ok {
+		vm.ip = ip
+		return
+	}
+
+	vm.ip = vmnet.nextLease(m.leases)
+	m.leases[mac] = vm.ip
+}
+
+func (m *Model) guestIP(vm *VirtualMachine) (interface{}, error) {
+	if vm.PowerState != poweredOn {
+		return nil, newFault(http.StatusConflict, "The virtual machine is not powered on")
+	}
+
+	if !vm.ToolsRunning || vm.ip == "" {
+		return nil, newFault(http.StatusInternalServerError, "Unable to get the IP address, VMware Tools is not running")
+	}
+
+	return &model.InlineResponse200{Ip: vm.ip}, nil
+}
+
+func (m *Model) nicIPs(vm *VirtualMachine, vmx *vmx.File) *model.NicIpStackAll {
+	res := &model.NicIpStackAll{}
+
+	for i, nic := range nics(vmx) {
+		stack := model.NicIpStack{Mac: nic.MacAddress}
+
+		if i == 0 && vm.PowerState == poweredOn && vm.ToolsRunning && vm.ip != "" {
+			prefix := 24
+			if vmnet := m.vmnet(nic.Vmnet); vmnet != nil && vmnet.Mask != "" {
+				prefix, _ = net.IPMask(net.ParseIP(vmnet.Mask).To4()).Size()
+			}
+			stack.Ip = []string{fmt.Sprintf("%s/%d", vm.ip, prefix)}
+		}
+
+		res.Nics = append(res.Nics, stack)
+	}
+
+	return res
+}
+
+func (m *Model) serveNIC(r *http.Request, vm *VirtualMachine, vmx *vmx.File, parts []string) (interface{}, error) {
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			devices := nics(vmx)
+			return &model.NicDevices{Num: len(devices), Nics: devices}, nil
+		case http.MethodPost:
+			if err := vm.checkPoweredOff(); err != nil {
+				return nil, err
+			}
+
+			for i := 0; i < maxNIC; i++ {
+				if !vmx.Has(fmt.Sprintf("ethernet%d.present", i)) {
+					return m.setNIC(r, vm, vmx, i, true)
+				}
+			}
+
+			return nil, newFault(http.StatusConflict, "The maximum number of network adapters is reached")
+		}
+
+		return nil, methodNotAllowed(r)
+	}
+
+	index, err := strconv.Atoi(parts[0])
+	if err != nil || index < 1 || index > maxNIC || !vmx.Has(fmt.Sprintf("ethernet%d.present", index-1)) {
+		return nil, notFound("network adapter", parts[0])
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		return m.setNIC(r, vm, vmx, index-1, false)
+	case http.MethodDelete:
+		if err := vm.checkPoweredOff(); err != nil {
+			return nil, err
+		}
+
+		vmx.RemovePrefix(fmt.Sprintf("ethernet%d.", index-1))
+
+		return nil, vmx.Save(vm.Path)
+	}
+
+	return nil, methodNotAllowed(r)
+}
+
+func (m *Model) setNIC(r *http.Request, vm *VirtualMachine, vmx *vmx.File, i int, create bool) (interface{}, error) {
+	var param model.NicDeviceParameter
+
+	if err := decode(r, &param); err != nil {
+		return nil, err
+	}
+
+	prefix := fmt.Sprintf("ethernet%d.", i)
+
+	switch param.Type {
+	case "bridged", "nat", "hostonly":
+		vmx.RemovePrefix(prefix + "vnet")
+	case "custom":
+		if m.vmnet(param.Vmnet) == nil {
+			return nil, notFound("virtual network", param.Vmnet)
+		}
+		vmx.Set(prefix+"vnet", param.Vmnet)
+	default:
+		return nil, newFault(http.StatusBadRequest, "Invalid network adapter type: %q", param.Type)
+	}
+
+	vmx.Set(prefix+"connectionType", param.Type)
+
+	if create {
+		vmx.Set(prefix+"present", "TRUE")
+		vmx.Set(prefix+"virtualDev", "e1000")
+		vmx.Set(prefix+"addressType", "generated")
+		vmx.Set(prefix+"generatedAddress", macAddress(vm.Path, i))
+	}
+
+	if err := vmx.Save(vm.Path); err != nil {
+		return nil, err
+	}
+
+	for _, nic := range nics(vmx) {
+		if nic.Index == i+1 {
+			return &nic, nil
+		}
+	}
+
+	return nil, notFound("network adapter", strconv.Itoa(i+1))
+}
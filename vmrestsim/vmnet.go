@@ -0,0 +1,379 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmrestsim
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Fred78290/vmrest-go-client/client/model"
+)
+
+// bridgedSubnet is the simulated subnet of the physical network used by vmnet0
+const bridgedSubnet = "192.168.1.0"
+
+// Vmnet is a simulated virtual network
+type Vmnet struct {
+	model.Network
+
+	portforwards []model.Portforward
+	mactoips     map[string]string
+}
+
+func newVmnet(name, kind, subnet string) *Vmnet {
+	vmnet := &Vmnet{
+		Network: model.Network{
+			Name: name,
+			Type: kind,
+			Dhcp: "false",
+		},
+		mactoips: map[string]string{},
+	}
+
+	if subnet != "" {
+		vmnet.Dhcp = "true"
+		vmnet.Subnet = subnet
+		vmnet.Mask = "255.255.255.0"
+	}
+
+	return vmnet
+}
+
+func defaultVmnets() []*Vmnet {
+	return []*Vmnet{
+		newVmnet("vmnet0", "bridged", ""),
+		newVmnet("vmnet1", "hostOnly", "192.168.80.0"),
+		newVmnet("vmnet8", "nat", "192.168.38.0"),
+	}
+}
+
+func (m *Model) vmnet(name string) *Vmnet {
+	for _, vmnet := range m.vmnets {
+		if vmnet.Name == name {
+			return vmnet
+		}
+	}
+
+	return nil
+}
+
+// nextLease returns the first address from .128 not used by a lease or a reservation
+func (vmnet *Vmnet) nextLease(leases map[string]string) string {
+	subnet := vmnet.Subnet
+	if subnet == "" {
+		subnet = bridgedSubnet
+	}
+
+	used := map[string]bool{}
+	for _, ip := range leases {
+		used[ip] = true
+	}
+	for _, ip := range vmnet.mactoips {
+		used[ip] = true
+	}
+
+	base := binary.BigEndian.Uint32(net.ParseIP(subnet).To4())
+
+	for host := uint32(128); host < 255; host++ {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, base+host)
+
+		if !used[ip.String()] {
+			return ip.String()
+		}
+	}
+
+	return ""
+}
+
+func (m *Model) serveVmnets(r *http.Request, parts []string) (interface{}, error) {
+	if parts[0] == "vmnets" {
+		if len(parts) != 1 || r.Method != http.MethodPost {
+			return nil, methodNotAllowed(r)
+		}
+
+		return m.createVmnet(r)
+	}
+
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			return nil, methodNotAllowed(r)
+		}
+
+		res := &model.Networks{Vmnets: []model.Network{}}
+		for _, vmnet := range m.vmnets {
+			res.Vmnets = append(res.Vmnets, vmnet.Network)
+		}
+		res.Num = len(res.Vmnets)
+
+		return res, nil
+	}
+
+	vmnet := m.vmnet(parts[1])
+	if vmnet == nil {
+		return nil, notFound("virtual network", parts[1])
+	}
+
+	if len(parts) == 2 {
+		if r.Method != http.MethodPut {
+			return nil, methodNotAllowed(r)
+		}
+
+		return m.updateVmnet(r, vmnet)
+	}
+
+	switch parts[2] {
+	case "mactoip":
+		return m.serveMacToIP(r, vmnet, parts[3:])
+	case "portforward":
+		return m.servePortforward(r, vmnet, parts[3:])
+	}
+
+	return nil, newFault(http.StatusNotFound, "Not found: %s", r.URL.Path)
+}
+
+func (m *Model) createVmnet(r *http.Request) (interface{}, error) {
+	var param model.CreateVmnetParameter
+
+	if err := decode(r, &param); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(param.Name, "vmnet") {
+		return nil, newFault(http.StatusBadRequest, "Invalid virtual network name: %q", param.Name)
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(param.Name, "vmnet"))
+	if err != nil || n < 0 || n > 19 {
+		return nil, newFault(http.StatusBadRequest, "Invalid virtual network name: %q", param.Name)
+	}
+
+	if m.vmnet(param.Name) != nil {
+		return nil, newFault(http.StatusConflict, "The virtual network already exists: %s", param.Name)
+	}
+
+	switch param.Type {
+	case "":
+		param.Type = "hostOnly"
+	case "hostOnly", "nat":
+	default:
+		return nil, newFault(http.StatusBadRequest, "Invalid virtual network type: %q", param.Type)
+	}
+
+	vmnet := newVmnet(param.Name, param.Type, fmt.Sprintf("192.168.%d.0", 100+n))
+
+	m.vmnets = append(m.vmnets, vmnet)
+
+	sort.Slice(m.vmnets, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(m.vmnets[i].Name, "vmnet"))
+		b, _ := strconv.Atoi(strings.TrimPrefix(m.vmnets[j].Name, "vmnet"))
+		return a < b
+	})
+
+	return &vmnet.Network, nil
+}
+
+func (m *Model) updateVmnet(r *http.Request, vmnet *Vmnet) (interface{}, error) {
+	var param model.Network
+
+	if err := decode(r, &param); err != nil {
+		return nil, err
+	}
+
+	if vmnet.Type == "bridged" {
+		return nil, newFault(http.StatusConflict, "The bridged virtual network cannot be changed: %s", vmnet.Name)
+	}
+
+	switch param.Type {
+	case "":
+		param.Type = vmnet.Type
+	case "hostOnly", "nat":
+	default:
+		return nil, newFault(http.StatusBadRequest, "Invalid virtual network type: %q", param.Type)
+	}
+
+	switch param.Dhcp {
+	case "":
+		param.Dhcp = vmnet.Dhcp
+	case "true", "false":
+	default:
+		return nil, newFault(http.StatusBadRequest, "Invalid DHCP setting: %q", param.Dhcp)
+	}
+
+	if param.Subnet == "" {
+		param.Subnet = vmnet.Subnet
+	}
+
+	if param.Mask == "" {
+		param.Mask = vmnet.Mask
+	}
+
+	subnet := net.ParseIP(param.Subnet).To4()
+	mask := net.IPMask(net.ParseIP(param.Mask).To4())
+
+	if subnet == nil || mask == nil || !subnet.Equal(subnet.Mask(mask)) {
+		return nil, newFault(http.StatusBadRequest, "Invalid subnet: %s/%s", param.Subnet, param.Mask)
+	}
+
+	if ones, _ := mask.Size(); ones == 0 || ones > 30 {
+		return nil, newFault(http.StatusBadRequest, "Invalid mask: %s", param.Mask)
+	}
+
+	param.Name = vmnet.Name
+	vmnet.Network = param
+
+	return &vmnet.Network, nil
+}
+
+func (m *Model) serveMacToIP(r *http.Request, vmnet *Vmnet, parts []string) (interface{}, error) {
+	if vmnet.Dhcp != "true" {
+		return nil, newFault(http.StatusConflict, "DHCP is not enabled on %s", vmnet.Name)
+	}
+
+	if len(parts) == 0 {
+		if r.Method != http.MethodGet {
+			return nil, methodNotAllowed(r)
+		}
+
+		res := &model.MactoIps{Mactoips: []model.MactoIp{}}
+		for mac, ip := range vmnet.mactoips {
+			res.Mactoips = append(res.Mactoips, model.MactoIp{Vmnet: vmnet.Name, Mac: mac, Ip: ip})
+		}
+
+		sort.Slice(res.Mactoips, func(i, j int) bool {
+			return res.Mactoips[i].Mac < res.Mactoips[j].Mac
+		})
+
+		res.Num = len(res.Mactoips)
+
+		return res, nil
+	}
+
+	if r.Method != http.MethodPut || len(parts) != 1 {
+		return nil, methodNotAllowed(r)
+	}
+
+	hw, err := net.ParseMAC(parts[0])
+	if err != nil {
+		return nil, newFault(http.StatusBadRequest, "Invalid MAC address: %q", parts[0])
+	}
+
+	var param model.MacToIpParameter
+
+	if err := decode(r, &param); err != nil {
+		return nil, err
+	}
+
+	mac := hw.String()
+
+	if param.IP == "" {
+		delete(vmnet.mactoips, mac)
+		return success, nil
+	}
+
+	if net.ParseIP(param.IP).To4() == nil {
+		return nil, newFault(http.StatusBadRequest, "Invalid IP address: %q", param.IP)
+	}
+
+	vmnet.mactoips[mac] = param.IP
+
+	return success, nil
+}
+
+func (m *Model) servePortforward(r *http.Request, vmnet *Vmnet, parts []string) (interface{}, error) {
+	if vmnet.Type != "nat" {
+		return nil, newFault(http.StatusConflict, "%s is not a NAT network", vmnet.Name)
+	}
+
+	if len(parts) == 0 {
+		if r.Method != http.MethodGet {
+			return nil, methodNotAllowed(r)
+		}
+
+		res := &model.Portforwards{PortForwardings: append([]model.Portforward{}, vmnet.portforwards...)}
+		res.Num = len(res.PortForwardings)
+
+		return res, nil
+	}
+
+	if len(parts) != 2 {
+		return nil, methodNotAllowed(r)
+	}
+
+	protocol := parts[0]
+	if protocol != "tcp" && protocol != "udp" {
+		return nil, newFault(http.StatusBadRequest, "Invalid protocol: %q", protocol)
+	}
+
+	port, err := strconv.Atoi(parts[1])
+	if err != nil || port < 1 || port > 65535 {
+		return nil, newFault(http.StatusBadRequest, "Invalid port: %q", parts[1])
+	}
+
+	index := -1
+	for i, pf := range vmnet.portforwards {
+		if pf.Protocol == protocol && pf.Port == port {
+			index = i
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var param model.PortforwardParameter
+
+		if err := decode(r, &param); err != nil {
+			return nil, err
+		}
+
+		if net.ParseIP(param.GuestIp).To4() == nil || param.GuestPort < 1 || param.GuestPort > 65535 {
+			return nil, newFault(http.StatusBadRequest, "Invalid guest: %s:%d", param.GuestIp, param.GuestPort)
+		}
+
+		pf := model.Portforward{
+			Port:     port,
+			Protocol: protocol,
+			Desc:     param.Desc,
+			Guest: &model.PortforwardGuest{
+				Ip:   param.GuestIp,
+				Port: param.GuestPort,
+			},
+		}
+
+		if index < 0 {
+			vmnet.portforwards = append(vmnet.portforwards, pf)
+		} else {
+			vmnet.portforwards[index] = pf
+		}
+
+		return success, nil
+	case http.MethodDelete:
+		if index < 0 {
+			return nil, notFound("port forwarding", fmt.Sprintf("%s/%d", protocol, port))
+		}
+
+		vmnet.portforwards = append(vmnet.portforwards[:index], vmnet.portforwards[index+1:]...)
+
+		return nil, nil
+	}
+
+	return nil, methodNotAllowed(r)
+}
@@ -0,0 +1,223 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmx
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ErrUnsupported is returned when a config spec field cannot be represented in a vmx file
+var ErrUnsupported = errors.New("not supported by the vmx format")
+
+// Unsupported returns the name of the config spec fields that cannot be represented in a vmx file
+func Unsupported(spec types.VirtualMachineConfigSpec) []string {
+	var fields []string
+
+	unsupported := func(set bool, name string) {
+		if set {
+			fields = append(fields, name)
+		}
+	}
+
+	allocation := func(a *types.ResourceAllocationInfo) bool {
+		return a != nil && (a.Reservation != nil || a.Limit != nil || a.ExpandableReservation != nil || (a.Shares != nil && a.Shares.Level != ""))
+	}
+
+	unsupported(spec.LatencySensitivity != nil, "latencySensitivity")
+	unsupported(spec.ScheduledHardwareUpgradeInfo != nil, "scheduledHardwareUpgradeInfo")
+	unsupported(allocation(spec.CpuAllocation), "cpuAllocation")
+	unsupported(allocation(spec.MemoryAllocation), "memoryAllocation")
+	unsupported(spec.MemoryReservationLockedToMax != nil && *spec.MemoryReservationLockedToMax, "memoryReservationLockedToMax")
+	unsupported(spec.CpuAffinity != nil, "cpuAffinity")
+	unsupported(spec.BootOptions != nil, "bootOptions")
+	unsupported(len(spec.DeviceChange) != 0, "deviceChange")
+
+	return fields
+}
+
+// BiosUUID converts an UUID to the uuid.bios format, "56 4d 1a 2b ... 3c-4d ..."
+func BiosUUID(uuid string) (string, error) {
+	id := strings.ToLower(strings.ReplaceAll(uuid, "-", ""))
+	if len(id) != 32 {
+		return "", fmt.Errorf("invalid UUID %q", uuid)
+	}
+
+	var parts []string
+
+	for i := 0; i < len(id); i += 2 {
+		if _, err := strconv.ParseUint(id[i:i+2], 16, 8); err != nil {
+			return "", fmt.Errorf("invalid UUID %q", uuid)
+		}
+		parts = append(parts, id[i:i+2])
+	}
+
+	return strings.Join(parts[:8], " ") + "-" + strings.Join(parts[8:], " "), nil
+}
+
+// UUID converts an uuid.bios value to the canonical UUID format, an empty string is returned if the value is invalid
+func UUID(bios string) string {
+	id := strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(bios))
+	if len(id) != 32 {
+		return ""
+	}
+
+	if _, err := BiosUUID(id); err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s-%s-%s-%s-%s", id[0:8], id[8:12], id[12:16], id[16:20], id[20:])
+}
+
+// settings maps the vmx keys to the config spec fields
+var settings = []string{
+	"displayName",
+	"guestOS",
+	"annotation",
+	"uuid.bios",
+	"numvcpus",
+	"cpuid.coresPerSocket",
+	"memsize",
+	"vhv.enable",
+	"vpmc.enable",
+	"mem.hotadd",
+	"vcpu.hotadd",
+	"tools.syncTime",
+	"virtualHW.version",
+	"firmware",
+}
+
+func isSetting(key string) bool {
+	for _, s := range settings {
+		if strings.EqualFold(s, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConfigSpec returns the config spec of the vmx, the keys not mapped to a config spec field are returned in ExtraConfig
+func (f *File) ConfigSpec() types.VirtualMachineConfigSpec {
+	atoi := func(key string) int64 {
+		n, _ := strconv.ParseInt(f.Get(key), 10, 64)
+		return n
+	}
+
+	spec := types.VirtualMachineConfigSpec{
+		Name:                f.Get("displayName"),
+		GuestId:             f.Get("guestOS"),
+		Annotation:          f.Get("annotation"),
+		Uuid:                UUID(f.Get("uuid.bios")),
+		NumCPUs:             int32(atoi("numvcpus")),
+		NumCoresPerSocket:   int32(atoi("cpuid.coresPerSocket")),
+		MemoryMB:            atoi("memsize"),
+		NestedHVEnabled:     f.Bool("vhv.enable"),
+		VPMCEnabled:         f.Bool("vpmc.enable"),
+		MemoryHotAddEnabled: f.Bool("mem.hotadd"),
+		CpuHotAddEnabled:    f.Bool("vcpu.hotadd"),
+		Firmware:            f.Get("firmware"),
+	}
+
+	if f.Has("virtualHW.version") {
+		spec.Version = "vmx-" + f.Get("virtualHW.version")
+	}
+
+	if sync := f.Bool("tools.syncTime"); sync != nil {
+		spec.Tools = &types.ToolsConfigInfo{SyncTimeWithHost: sync}
+	}
+
+	for _, e := range f.Entries() {
+		if !isSetting(e.Key) {
+			spec.ExtraConfig = append(spec.ExtraConfig, &types.OptionValue{Key: e.Key, Value: e.Value})
+		}
+	}
+
+	return spec
+}
+
+// ApplyConfigSpec applies the fields set in the config spec to the vmx,
+// an ExtraConfig option with an empty value removes the key.
+func (f *File) ApplyConfigSpec(spec types.VirtualMachineConfigSpec) error {
+	if fields := Unsupported(spec); len(fields) != 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupported, strings.Join(fields, ", "))
+	}
+
+	set := func(ok bool, key, value string) {
+		if ok {
+			f.Set(key, value)
+		}
+	}
+
+	flag := func(key string, value *bool) {
+		if value != nil {
+			f.SetBool(key, *value)
+		}
+	}
+
+	if spec.Uuid != "" {
+		uuid, err := BiosUUID(spec.Uuid)
+		if err != nil {
+			return err
+		}
+
+		f.Set("uuid.bios", uuid)
+	}
+
+	if spec.Version != "" {
+		version := strings.TrimPrefix(spec.Version, "vmx-")
+		if _, err := strconv.Atoi(version); err != nil {
+			return fmt.Errorf("invalid hardware version %q", spec.Version)
+		}
+
+		f.Set("virtualHW.version", version)
+	}
+
+	set(spec.Name != "", "displayName", spec.Name)
+	set(spec.GuestId != "", "guestOS", spec.GuestId)
+	set(spec.Annotation != "", "annotation", spec.Annotation)
+	set(spec.NumCPUs != 0, "numvcpus", strconv.Itoa(int(spec.NumCPUs)))
+	set(spec.NumCoresPerSocket != 0, "cpuid.coresPerSocket", strconv.Itoa(int(spec.NumCoresPerSocket)))
+	set(spec.MemoryMB != 0, "memsize", strconv.FormatInt(spec.MemoryMB, 10))
+	set(spec.Firmware != "", "firmware", spec.Firmware)
+
+	flag("vhv.enable", spec.NestedHVEnabled)
+	flag("vpmc.enable", spec.VPMCEnabled)
+	flag("mem.hotadd", spec.MemoryHotAddEnabled)
+	flag("vcpu.hotadd", spec.CpuHotAddEnabled)
+
+	if spec.Tools != nil {
+		flag("tools.syncTime", spec.Tools.SyncTimeWithHost)
+	}
+
+	for _, option := range spec.ExtraConfig {
+		o := option.GetOptionValue()
+		value := fmt.Sprintf("%v", o.Value)
+
+		if o.Value == nil || value == "" {
+			f.Remove(o.Key)
+		} else {
+			f.Set(o.Key, value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,368 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package vmx reads and writes VMware .vmx files, and the files sharing their
+format such as .vmsd snapshot databases and the inventory.vmls library.
+
+Keys are case insensitive, the order of the entries, the comments, the blank
+lines and the case of the keys are preserved: a File written without being
+modified is byte for byte identical to the file it was parsed from. When a key
+is duplicated its last value is used, as VMware does.
+*/
+package vmx
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Entry is a setting of a vmx file
+type Entry struct {
+	Key   string
+	Value string
+}
+
+type line struct {
+	raw   string // the line as read, empty once the entry is modified
+	entry *Entry
+}
+
+// File is the content of a vmx file
+type File struct {
+	lines []*line
+	eol   string
+	final bool // the last line ends with eol
+}
+
+// New returns an empty File
+func New() *File {
+	return &File{eol: "\n", final: true}
+}
+
+// Parse reads a vmx file from r
+func Parse(r io.Reader) (*File, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	f := New()
+
+	if len(b) == 0 {
+		return f, nil
+	}
+
+	if i := bytes.IndexByte(b, '\n'); i > 0 && b[i-1] == '\r' {
+		f.eol = "\r\n"
+	}
+
+	f.final = bytes.HasSuffix(b, []byte("\n"))
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 64*1024), len(b)+1)
+
+	for n := 1; scanner.Scan(); n++ {
+		raw := strings.TrimSuffix(scanner.Text(), "\r")
+		l := &line{raw: raw}
+		text := strings.TrimSpace(raw)
+
+		if text != "" && !strings.HasPrefix(text, "#") {
+			kv := strings.SplitN(text, "=", 2)
+			if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+				return nil, fmt.Errorf("line %d: invalid entry %q", n, text)
+			}
+
+			value := strings.TrimSpace(kv[1])
+			if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+				value = value[1 : len(value)-1]
+			}
+
+			l.entry = &Entry{
+				Key:   strings.TrimSpace(kv[0]),
+				Value: Decode(value),
+			}
+		}
+
+		f.lines = append(f.lines, l)
+	}
+
+	return f, scanner.Err()
+}
+
+// Load reads the named vmx file
+func Load(name string) (*File, error) {
+	r, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	f, err := Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return f, nil
+}
+
+// Write writes the vmx file to w, the lines that were not modified are written as read
+func (f *File) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for i, l := range f.lines {
+		if l.raw != "" || l.entry == nil {
+			_, _ = bw.WriteString(l.raw)
+		} else {
+			_, _ = fmt.Fprintf(bw, "%s = \"%s\"", l.entry.Key, Encode(l.entry.Value))
+		}
+
+		if i < len(f.lines)-1 || f.final {
+			_, _ = bw.WriteString(f.eol)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Bytes returns the content of the vmx file
+func (f *File) Bytes() []byte {
+	var b bytes.Buffer
+	_ = f.Write(&b)
+	return b.Bytes()
+}
+
+// Save writes the vmx file to name, the file is replaced once written
+func (f *File) Save(name string) error {
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(name); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-")
+	if err != nil {
+		return err
+	}
+
+	err = f.Write(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// Copy returns a copy of the vmx file
+func (f *File) Copy() *File {
+	c := &File{eol: f.eol, final: f.final}
+
+	for _, l := range f.lines {
+		n := &line{raw: l.raw}
+		if l.entry != nil {
+			e := *l.entry
+			n.entry = &e
+		}
+		c.lines = append(c.lines, n)
+	}
+
+	return c
+}
+
+// find returns the line of the key, the last one if the key is duplicated as VMware uses the last value
+func (f *File) find(key string) *line {
+	for i := len(f.lines) - 1; i >= 0; i-- {
+		if l := f.lines[i]; l.entry != nil && strings.EqualFold(l.entry.Key, key) {
+			return l
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the value of the key and whether it is set
+func (f *File) Lookup(key string) (string, bool) {
+	if l := f.find(key); l != nil {
+		return l.entry.Value, true
+	}
+
+	return "", false
+}
+
+// Get returns the value of the key, an empty string if it is not set
+func (f *File) Get(key string) string {
+	value, _ := f.Lookup(key)
+	return value
+}
+
+// Has returns true if the key is set
+func (f *File) Has(key string) bool {
+	return f.find(key) != nil
+}
+
+// Set changes the value of the key, or adds the key at the end of the file
+func (f *File) Set(key, value string) {
+	if l := f.find(key); l != nil {
+		if l.entry.Value != value {
+			l.entry.Value = value
+			l.raw = ""
+		}
+		return
+	}
+
+	f.lines = append(f.lines, &line{entry: &Entry{Key: key, Value: value}})
+}
+
+// Rename changes the name of the key, it returns false if the key is not set
+func (f *File) Rename(key, name string) bool {
+	l := f.find(key)
+	if l == nil {
+		return false
+	}
+
+	if l.entry.Key != name {
+		l.entry.Key = name
+		l.raw = ""
+	}
+
+	return true
+}
+
+// RemoveFunc deletes the entries matching the given function and returns how many were removed
+func (f *File) RemoveFunc(match func(e Entry) bool) int {
+	lines := f.lines[:0]
+	n := 0
+
+	for _, l := range f.lines {
+		if l.entry != nil && match(*l.entry) {
+			n++
+			continue
+		}
+		lines = append(lines, l)
+	}
+
+	f.lines = lines
+
+	return n
+}
+
+// Remove deletes the key, it returns false if the key is not set
+func (f *File) Remove(key string) bool {
+	return f.RemoveFunc(func(e Entry) bool {
+		return strings.EqualFold(e.Key, key)
+	}) != 0
+}
+
+// RemovePrefix deletes the keys starting with the given prefix and returns how many were removed
+func (f *File) RemovePrefix(prefix string) int {
+	prefix = strings.ToLower(prefix)
+
+	return f.RemoveFunc(func(e Entry) bool {
+		return strings.HasPrefix(strings.ToLower(e.Key), prefix)
+	})
+}
+
+// Entries returns the settings of the vmx file, in order
+func (f *File) Entries() []Entry {
+	var entries []Entry
+
+	for _, l := range f.lines {
+		if l.entry != nil {
+			entries = append(entries, *l.entry)
+		}
+	}
+
+	return entries
+}
+
+// Bool returns the value of a boolean key, nil if it is not set or not a boolean
+func (f *File) Bool(key string) *bool {
+	value, ok := f.Lookup(key)
+	if !ok {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(strings.ToLower(value))
+	if err != nil {
+		return nil
+	}
+
+	return &b
+}
+
+// SetBool changes the value of a boolean key
+func (f *File) SetBool(key string, value bool) {
+	f.Set(key, FormatBool(value))
+}
+
+// FormatBool returns the vmx representation of a boolean, "TRUE" or "FALSE"
+func FormatBool(value bool) string {
+	return strings.ToUpper(strconv.FormatBool(value))
+}
+
+// Decode returns a value with its "|XX" hexadecimal escapes decoded
+func Decode(value string) string {
+	if !strings.Contains(value, "|") {
+		return value
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] == '|' && i+2 < len(value) {
+			if c, err := strconv.ParseUint(value[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+
+	return b.String()
+}
+
+// Encode escapes the characters of a value that cannot be written as is
+func Encode(value string) string {
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case c == '|', c == '"', c < 0x20, c == 0x7f:
+			fmt.Fprintf(&b, "|%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2023 Fred78290, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vmx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []string{
+		"",
+		"\n",
+		".encoding = \"UTF-8\"\nconfig.version = \"8\"\n",
+		// CRLF line endings, comments, blank lines and no final line ending
+		"# comment\r\n.encoding = \"UTF-8\"\r\n\r\n  # indented comment\r\ndisplayName = \"vm\"",
+		// escapes, unquoted values, spacing and case
+		"annotation = \"say |22hello|22|0Aworld|7C\"\nNumVCPUs=2\n  memsize   =   \"1024\"  \n",
+	}
+
+	for _, test := range tests {
+		f, err := Parse(bytes.NewReader([]byte(test)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if out := string(f.Bytes()); out != test {
+			t.Errorf("expected %q, got %q", test, out)
+		}
+
+		if out := string(f.Copy().Bytes()); out != test {
+			t.Errorf("copy: expected %q, got %q", test, out)
+		}
+	}
+}
+
+func TestEscapes(t *testing.T) {
+	f, err := Parse(bytes.NewReader([]byte("# c\r\nannotation = \"say |22hello|22|0Aworld|7C\"\r\nnumvcpus = \"2\"\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := f.Get("Annotation"); v != "say \"hello\"\nworld|" {
+		t.Errorf("unexpected value %q", v)
+	}
+
+	// the modified entries are encoded, the other lines and the line endings are kept
+	f.Set("annotation", "a \"quoted\" value|")
+
+	expect := "# c\r\nannotation = \"a |22quoted|22 value|7C\"\r\nnumvcpus = \"2\"\r\n"
+	if out := string(f.Bytes()); out != expect {
+		t.Errorf("expected %q, got %q", expect, out)
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	f, err := Parse(bytes.NewReader([]byte("memsize = \"1024\"\nnumvcpus = \"1\"\nMemSize = \"2048\"\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// VMware uses the last value of a duplicated key
+	if v := f.Get("memsize"); v != "2048" {
+		t.Errorf("expected 2048, got %s", v)
+	}
+
+	f.Set("memsize", "4096")
+
+	expect := "memsize = \"1024\"\nnumvcpus = \"1\"\nMemSize = \"4096\"\n"
+	if out := string(f.Bytes()); out != expect {
+		t.Errorf("expected %q, got %q", expect, out)
+	}
+
+	if !f.Remove("memsize") || f.Has("memsize") {
+		t.Error("expected every memsize entry to be removed")
+	}
+}